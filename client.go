@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+// clientPollInterval is how often a starting platform client is checked.
+var clientPollInterval = time.Second
+
+// clientStartCmd returns the command that starts a platform client without
+// launching a game, or nil when there is no known way to on goos.
+func clientStartCmd(platform, goos string) *exec.Cmd {
+	client, ok := platformClients[platform]
+	if !ok {
+		return nil
+	}
+	switch goos {
+	case "darwin":
+		if client.MacApp == "" {
+			return nil
+		}
+		args := []string{"-g", "-a", client.MacApp}
+		if len(client.StartArgs) > 0 {
+			args = append(append(args, "--args"), client.StartArgs...)
+		}
+		return exec.Command("open", args...)
+	case "windows":
+		for _, dir := range []string{os.Getenv("ProgramFiles(x86)"), os.Getenv("ProgramFiles")} {
+			if dir == "" || client.WindowsExe == "" {
+				continue
+			}
+			if exe := filepath.Join(dir, client.WindowsExe); fileExists(exe) {
+				return exec.Command(exe, client.StartArgs...)
+			}
+		}
+		return nil
+	default:
+		// Steam is the only one of these clients with a Linux build.
+		if platform != "steam" {
+			return nil
+		}
+		switch detectSteamPackaging() {
+		case packagingFlatpak:
+			return exec.Command("flatpak", append([]string{"run", steamFlatpakID}, client.StartArgs...)...)
+		case packagingSnap:
+			return exec.Command("snap", append([]string{"run", "steam"}, client.StartArgs...)...)
+		}
+		return exec.Command("steam", client.StartArgs...)
+	}
+}
+
+// startPlatformClient starts the platform's client and blocks until it is
+// ready to accept a game URL. A client that can't be started directly is left
+// to its URL handler, as before.
+func (app *App) startPlatformClient(platform string) error {
+	client := platformClients[platform]
+	cmd := clientStartCmd(platform, runtime.GOOS)
+	if cmd == nil {
+		log.Printf("Warning: don't know how to start %s directly — its URL handler will start it, adding delay", client.Name)
+		return nil
+	}
+
+	log.Printf("Starting %s and waiting for it to be ready", client.Name)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not start %s: %w", client.Name, err)
+	}
+	// The client (or the opener that started it) is reaped in the background;
+	// readiness is judged from the process list.
+	go cmd.Wait()
+
+	var ready func() bool
+	if platform == "steam" && runtime.GOOS == "linux" {
+		ready = steamPidReady
+	}
+	cfg := app.currentConfig()
+	timeout := time.Duration(cfg.ClientReadyTimeout) * time.Second
+	settle := time.Duration(cfg.ClientSettleTime) * time.Second
+	running := func() bool { return app.isPlatformRunning(platform) }
+	if err := waitForClient(running, ready, timeout, settle); err != nil {
+		return fmt.Errorf("%s %w", client.Name, err)
+	}
+	log.Printf("%s is ready", client.Name)
+	return nil
+}
+
+// waitForClient polls until ready reports true, or running has reported true
+// continuously for settle — a client that is still updating tends to restart
+// itself, so a process that merely appeared isn't enough. It gives up after
+// timeout.
+func waitForClient(running, ready func() bool, timeout, settle time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	var since time.Time
+	for {
+		if ready != nil && ready() {
+			return nil
+		}
+		if running() {
+			if since.IsZero() {
+				since = time.Now()
+			}
+			if time.Since(since) >= settle {
+				return nil
+			}
+		} else {
+			since = time.Time{}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("was not ready within %s", timeout)
+		}
+		time.Sleep(clientPollInterval)
+	}
+}
+
+// steamPidReady reports whether Linux Steam's pid file names a live process.
+// Steam keeps ~/.steam/steam.pid pointing at its client while it runs.
+func steamPidReady() bool {
+	home, _ := os.UserHomeDir()
+	return pidFileLive(filepath.Join(home, ".steam", "steam.pid"))
+}
+
+// pidFileLive reports whether path holds the PID of a running process.
+func pidFileLive(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return false
+	}
+	alive, err := process.PidExists(int32(pid))
+	return err == nil && alive
+}
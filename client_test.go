@@ -0,0 +1,143 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Starting platform clients
+// ============================================================================
+
+func fastClientPolling(t *testing.T) {
+	t.Helper()
+	old := clientPollInterval
+	clientPollInterval = time.Millisecond
+	t.Cleanup(func() { clientPollInterval = old })
+}
+
+func TestWaitForClient_WaitsForStableProcess(t *testing.T) {
+	fastClientPolling(t)
+	// The client appears, restarts once (as Steam does after updating), then
+	// stays up.
+	polls := 0
+	running := func() bool {
+		polls++
+		return polls != 5
+	}
+	start := time.Now()
+	if err := waitForClient(running, nil, time.Second, 20*time.Millisecond); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if polls <= 5 {
+		t.Errorf("expected readiness only after the restart, got %d polls", polls)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("returned before the client had been up for the settle time")
+	}
+}
+
+func TestWaitForClient_ReadySignalShortCircuits(t *testing.T) {
+	fastClientPolling(t)
+	running := func() bool { return false }
+	ready := func() bool { return true }
+	if err := waitForClient(running, ready, time.Second, time.Hour); err != nil {
+		t.Errorf("expected ready signal to be trusted, got %v", err)
+	}
+}
+
+func TestWaitForClient_TimesOut(t *testing.T) {
+	fastClientPolling(t)
+	err := waitForClient(func() bool { return false }, nil, 10*time.Millisecond, 0)
+	if err == nil || !strings.Contains(err.Error(), "not ready within") {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+}
+
+func TestClientStartCmd_Darwin(t *testing.T) {
+	cmd := clientStartCmd("steam", "darwin")
+	if got := strings.Join(cmd.Args, " "); got != "open -g -a Steam --args -silent" {
+		t.Errorf("steam on darwin: got %q", got)
+	}
+	cmd = clientStartCmd("battlenet", "darwin")
+	if got := strings.Join(cmd.Args, " "); got != "open -g -a Battle.net" {
+		t.Errorf("battlenet on darwin: got %q", got)
+	}
+	if cmd := clientStartCmd("ubisoft", "darwin"); cmd != nil {
+		t.Errorf("ubisoft has no macOS client, got %v", cmd.Args)
+	}
+}
+
+func TestClientStartCmd_LinuxSteamPackaging(t *testing.T) {
+	cases := map[string]string{
+		packagingNative:  "steam -silent",
+		packagingFlatpak: "flatpak run com.valvesoftware.Steam -silent",
+		packagingSnap:    "snap run steam -silent",
+	}
+	for packaging, want := range cases {
+		pinSteamPackaging(t, packaging)
+		if got := strings.Join(clientStartCmd("steam", "linux").Args, " "); got != want {
+			t.Errorf("%s: got %q, want %q", packaging, got, want)
+		}
+	}
+	if cmd := clientStartCmd("epic", "linux"); cmd != nil {
+		t.Errorf("epic has no Linux client, got %v", cmd.Args)
+	}
+	if cmd := clientStartCmd("direct", "linux"); cmd != nil {
+		t.Errorf("direct has no client, got %v", cmd.Args)
+	}
+}
+
+func TestPidFileLive(t *testing.T) {
+	dir := t.TempDir()
+	live := filepath.Join(dir, "live.pid")
+	os.WriteFile(live, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644)
+	if !pidFileLive(live) {
+		t.Error("expected our own pid to be live")
+	}
+	garbage := filepath.Join(dir, "garbage.pid")
+	os.WriteFile(garbage, []byte("not a pid"), 0644)
+	if pidFileLive(garbage) {
+		t.Error("expected a malformed pid file to be ignored")
+	}
+	if pidFileLive(filepath.Join(dir, "missing.pid")) {
+		t.Error("expected a missing pid file to be ignored")
+	}
+}
+
+func TestLaunchGameByStruct_ClientStartFailure(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("starts the client through the Linux steam binary")
+	}
+	if _, err := exec.LookPath("steam"); err == nil {
+		t.Skip("would start the real Steam client")
+	}
+	pinSteamPackaging(t, packagingNative)
+	game := Game{GameName: "Portal", GamePath: "steam://rungameid/400", LaunchMethod: "steam"}
+	app := appWithGames([]Game{game})
+	if app.isPlatformRunning("steam") {
+		t.Skip("Steam is already running")
+	}
+	app.config.StartClients = true
+	app.config.ClientReadyTimeout = 1
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+
+	app.launchGameByStruct(game)
+
+	entries, err := readHistory(app.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Event != historyLaunchFailed || !strings.Contains(entries[0].Detail, "could not start Steam") {
+		t.Errorf("expected one launch_failed entry for the client, got %+v", entries)
+	}
+	if app.gameIssues["Portal"] == "" {
+		t.Error("expected the failure to be shown against the game")
+	}
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configDropInDir is the directory next to config.yaml whose *.yaml files
+// are merged into it.
+const configDropInDir = "config.d"
+
+// configIncludes describes the files merged into config.yaml: those listed
+// under include: and the config.d drop-ins. The launcher only reads them;
+// edits made in the manager window are saved to config.yaml.
+type configIncludes struct {
+	Files     []string          // in merge order; config.yaml comes after them
+	Data      map[string][]byte // each file's contents when loaded, nil if it couldn't be read
+	Base      *Config           // what the files give without config.yaml
+	GameFiles map[string]string // game ID → file that defines the game
+}
+
+// configLayerPaths returns the files merged into the config at path, in
+// order: each include: entry as listed (relative to path's directory; glob
+// patterns expand in name order), then config.d/*.yaml by name. A file is
+// only merged once, and config.yaml itself never. Patterns with a wildcard
+// in a directory name are skipped, as the watcher couldn't follow them.
+func configLayerPaths(path string, include []string) []string {
+	dir := filepath.Dir(path)
+	var paths []string
+	add := func(p string) {
+		p = filepath.Clean(p)
+		if p != filepath.Clean(path) && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	for _, pattern := range include {
+		if hasDirWildcard(pattern) {
+			continue // reported by validateConfig
+		}
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, _ := filepath.Glob(pattern)
+		if len(matches) == 0 && !strings.ContainsAny(pattern, "*?[") {
+			matches = []string{pattern} // reported as missing when read
+		}
+		for _, m := range matches {
+			add(m)
+		}
+	}
+	dropIns := filepath.Join(dir, configDropInDir)
+	entries, _ := os.ReadDir(dropIns)
+	for _, e := range entries {
+		if !e.IsDir() && isConfigDropIn(e.Name()) {
+			add(filepath.Join(dropIns, e.Name()))
+		}
+	}
+	return paths
+}
+
+// hasDirWildcard reports whether an include pattern has a glob wildcard
+// outside its file name.
+func hasDirWildcard(pattern string) bool {
+	return strings.ContainsAny(filepath.Dir(pattern), "*?[")
+}
+
+// isConfigDropIn reports whether name is a file config.d merges, skipping
+// editor swap and backup files.
+func isConfigDropIn(name string) bool {
+	name = filepath.Base(name)
+	ext := filepath.Ext(name)
+	return !strings.HasPrefix(name, ".") && (ext == ".yaml" || ext == ".yml")
+}
+
+// parseLayeredConfig parses config.yaml (data, read from path) like
+// parseConfig, merged over the files it includes and the config.d drop-ins.
+// Without any such files it is parseConfig and inc is nil. Problems in the
+// other files are reported with their file name; one that can't be read or
+// parsed is left out.
+func parseLayeredConfig(path string, data []byte, cfg *Config) (changes []string, errs []ConfigError, ok bool, inc *configIncludes) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, yamlErrors(err), false, nil
+	}
+	doc := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(root.Content) > 0 {
+		doc = root.Content[0]
+	}
+	var head struct {
+		Version int      `yaml:"version"`
+		Include []string `yaml:"include"`
+	}
+	if doc.Kind != yaml.MappingNode || doc.Decode(&head) != nil {
+		changes, errs, ok = parseConfig(data, cfg)
+		return changes, errs, ok, nil
+	}
+	paths := configLayerPaths(path, head.Include)
+	if len(paths) == 0 {
+		changes, errs, ok = parseConfig(data, cfg)
+		return changes, errs, ok, nil
+	}
+
+	inc = &configIncludes{Files: paths, Data: make(map[string][]byte), GameFiles: make(map[string]string)}
+	m := &configMerge{doc: &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, files: make(map[*yaml.Node]string), inc: inc}
+	for _, p := range paths {
+		layer, err := os.ReadFile(p)
+		if err != nil {
+			errs = append(errs, ConfigError{File: p, Msg: fmt.Sprintf("can't be read: %v", err)})
+			continue
+		}
+		inc.Data[p] = layer
+		var layerRoot yaml.Node
+		if err := yaml.Unmarshal(layer, &layerRoot); err != nil {
+			for _, e := range yamlErrors(err) {
+				e.File = p
+				errs = append(errs, e)
+			}
+			continue
+		}
+		if len(layerRoot.Content) == 0 {
+			continue
+		}
+		if layerRoot.Content[0].Kind != yaml.MappingNode {
+			errs = append(errs, ConfigError{File: p, Line: layerRoot.Content[0].Line, Msg: "must be a mapping of config keys"})
+			continue
+		}
+		m.addLayer(p, layerRoot.Content[0], false)
+	}
+	errs = append(errs, m.errs...)
+	m.errs = nil
+
+	// What the other files give on their own, upgraded the same way as the
+	// merged config so their games compare equal to it.
+	inc.Base = defaultConfig()
+	inc.Base.Version = 0
+	if err := m.doc.Decode(inc.Base); err != nil {
+		return nil, append(errs, yamlErrors(err)...), false, nil
+	}
+	if inc.Base.Version = head.Version; head.Version < currentConfigVersion {
+		migrateConfig(inc.Base)
+	}
+
+	m.addLayer(path, doc, true)
+	cfg.Version = 0
+	if err := m.doc.Decode(cfg); err != nil {
+		return nil, append(errs, yamlErrors(err)...), false, nil
+	}
+	changes = migrateConfig(cfg)
+	errs = append(errs, m.errs...)
+	return changes, append(errs, validateConfig(m.doc, cfg, m.files)...), true, inc
+}
+
+// configMerge builds the merged config document one file at a time. Later
+// files win: their keys replace earlier ones, except that maps such as
+// emulators are merged key by key, and a game with the same id, or failing
+// that the same game_name, as one from an earlier file is merged into it key
+// by key instead of being added.
+type configMerge struct {
+	doc   *yaml.Node
+	files map[*yaml.Node]string // node → the included file it came from
+	inc   *configIncludes
+	names map[string]int // how often each game name got an ID derived from it
+	errs  []ConfigError  // games included without an id, and overrides that match nothing
+}
+
+// addLayer merges the file at path, whose top-level mapping is layer. main
+// is true for config.yaml.
+func (m *configMerge) addLayer(path string, layer *yaml.Node, main bool) {
+	if !main {
+		walkYAML(layer, func(n *yaml.Node) { m.files[n] = path })
+	}
+	for i := 0; i+1 < len(layer.Content); i += 2 {
+		key, value := layer.Content[i], layer.Content[i+1]
+		switch {
+		case !main && (key.Value == "version" || key.Value == "include"):
+			// Only config.yaml's count; includes don't nest.
+		case key.Value == "games" && value.Kind == yaml.SequenceNode:
+			m.addGames(path, value, main)
+		default:
+			old := mappingValue(m.doc, key.Value)
+			if old != nil && old.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode {
+				merged := *old
+				merged.Content = slices.Clone(old.Content)
+				mergeYAMLMapping(&merged, value)
+				value = &merged
+			}
+			setMappingValue(m.doc, key, value)
+		}
+	}
+}
+
+func (m *configMerge) addGames(path string, games *yaml.Node, main bool) {
+	merged := mappingValue(m.doc, "games")
+	if merged == nil {
+		merged = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		setMappingValue(m.doc, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "games"}, merged)
+	}
+	earlier := len(merged.Content) // games from this file don't merge with each other
+	for i, item := range games.Content {
+		if j := matchingGameNode(merged.Content[:earlier], item); j >= 0 {
+			mergeYAMLMapping(merged.Content[j], item)
+			continue
+		}
+		// An override whose id is stale, e.g. because the included game
+		// has since been given one, still finds its game by name.
+		if main && mappingValue(item, "game_path") == nil {
+			if j := gameNodeNamed(merged.Content[:earlier], item); j >= 0 {
+				override := *item
+				override.Content = slices.Clone(item.Content)
+				k := mappingKeyIndex(override.Content, "id")
+				mergeYAMLMapping(merged.Content[j], &yaml.Node{Kind: yaml.MappingNode, Content: slices.Delete(override.Content, k, k+2)})
+				continue
+			}
+			if mappingValue(item, "id") != nil {
+				m.errs = append(m.errs, ConfigError{Line: item.Line, Column: item.Column, Path: fmt.Sprintf("games[%d]", len(merged.Content)),
+					Msg: "changes a game that isn't in the included files; was it renamed or removed there?"})
+			}
+		}
+		game := *item
+		game.Content = slices.Clone(item.Content)
+		if !main {
+			m.files[&game] = path
+			id := mappingValue(&game, "id")
+			if id == nil {
+				// Included files aren't written to, so their games get an
+				// ID derived from the name that stays the same every load,
+				// but not across a rename.
+				m.errs = append(m.errs, ConfigError{File: path, Line: item.Line, Column: item.Column, Path: fmt.Sprintf("games[%d].id", i),
+					Msg: "is required in included files, so this machine's changes and launch history survive a rename"})
+				id = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: m.derivedGameID(&game)}
+				game.Content = append(game.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "id"}, id)
+			}
+			m.inc.GameFiles[id.Value] = path
+		}
+		merged.Content = append(merged.Content, &game)
+	}
+}
+
+// derivedGameID returns the ID for an included game without one.
+func (m *configMerge) derivedGameID(game *yaml.Node) string {
+	name := ""
+	if v := mappingValue(game, "game_name"); v != nil {
+		name = v.Value
+	}
+	if m.names == nil {
+		m.names = make(map[string]int)
+	}
+	m.names[name]++
+	if n := m.names[name]; n > 1 {
+		name = fmt.Sprintf("%s#%d", name, n)
+	}
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:4])
+}
+
+// matchingGameNode returns the index of the game in games that item
+// overrides: the one with its id, or, if it has none, its game_name.
+func matchingGameNode(games []*yaml.Node, item *yaml.Node) int {
+	key := "id"
+	v := mappingValue(item, key)
+	if v == nil {
+		key = "game_name"
+		if v = mappingValue(item, key); v == nil {
+			return -1
+		}
+	}
+	for j, game := range games {
+		if old := mappingValue(game, key); old != nil && old.Value == v.Value {
+			return j
+		}
+	}
+	return -1
+}
+
+// gameNodeNamed returns the index of the game in games with item's
+// game_name, or -1.
+func gameNodeNamed(games []*yaml.Node, item *yaml.Node) int {
+	if name := mappingValue(item, "game_name"); name != nil {
+		return yamlItemIndex(&yaml.Node{Kind: yaml.SequenceNode, Content: games}, "game_name", name.Value)
+	}
+	return -1
+}
+
+// mergeYAMLMapping sets each of src's keys in dst.
+func mergeYAMLMapping(dst, src *yaml.Node) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		setMappingValue(dst, src.Content[i], src.Content[i+1])
+	}
+}
+
+// setMappingValue replaces key's value in mapping, or adds the key.
+func setMappingValue(mapping, key, value *yaml.Node) {
+	if i := mappingKeyIndex(mapping.Content, key.Value); i >= 0 {
+		mapping.Content[i+1] = value
+		return
+	}
+	mapping.Content = append(mapping.Content, key, value)
+}
+
+// walkYAML calls f for n and every node below it.
+func walkYAML(n *yaml.Node, f func(*yaml.Node)) {
+	f(n)
+	for _, c := range n.Content {
+		walkYAML(c, f)
+	}
+}
+
+// unchanged reports whether the files merged into the config at path are
+// the same ones, with the same contents, as when inc was loaded.
+func (inc *configIncludes) unchanged(path string, include []string) bool {
+	if !slices.Equal(configLayerPaths(path, include), inc.Files) {
+		return false
+	}
+	for _, p := range inc.Files {
+		data, err := os.ReadFile(p)
+		if (err == nil) != (inc.Data[p] != nil) || !bytes.Equal(data, inc.Data[p]) {
+			return false
+		}
+	}
+	return true
+}
+
+// marshalLayeredConfig encodes what config.yaml must hold, laid over
+// original, for the merged config to come out as cfg: its settings, its own
+// games in full and, for games from the included files, only the keys that
+// differ from those files.
+func marshalLayeredConfig(original []byte, cfg, base *Config) ([]byte, error) {
+	own := *cfg
+	own.Games = nil
+	overrides := make(map[int][]string) // position in own.Games → changed keys
+	for _, game := range cfg.Games {
+		i := gameIndex(base.Games, game.ID)
+		if i < 0 {
+			own.Games = append(own.Games, game)
+			continue
+		}
+		if changes := gameChanges(base.Games[i], game); len(changes) > 0 {
+			overrides[len(own.Games)] = changes
+			own.Games = append(own.Games, game)
+		}
+	}
+
+	var updated yaml.Node
+	if err := updated.Encode(&own); err != nil {
+		return nil, err
+	}
+	if len(own.Games) == 0 {
+		// A games list here adds to the included ones, so an empty one
+		// says nothing.
+		i := mappingKeyIndex(updated.Content, "games")
+		updated.Content = slices.Delete(updated.Content, i, i+2)
+	} else if games := mappingValue(&updated, "games"); games != nil {
+		fields := yamlFields(reflect.TypeFor[Game]())
+		for i, changes := range overrides {
+			item := games.Content[i]
+			var content []*yaml.Node
+			for k := 0; k+1 < len(item.Content); k += 2 {
+				key := item.Content[k].Value
+				if key == "id" || key == "game_name" || slices.Contains(changes, key) {
+					content = append(content, item.Content[k], item.Content[k+1])
+				}
+			}
+			// A cleared omitempty field isn't encoded, and leaving it out
+			// would bring back the included file's value.
+			for _, key := range changes {
+				if mappingValue(item, key) != nil {
+					continue
+				}
+				var empty yaml.Node
+				if err := empty.Encode(reflect.Zero(fields[key]).Interface()); err != nil {
+					return nil, err
+				}
+				if empty.Kind != yaml.ScalarNode {
+					empty.Style = yaml.FlowStyle
+				}
+				content = append(content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &empty)
+			}
+			item.Content = content
+		}
+	}
+
+	// Settings the included files already give aren't repeated here.
+	defaults := *base
+	defaults.Version = 0
+	return marshalPreservingYAML(original, &updated, &defaults)
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Config includes and config.d
+// ============================================================================
+
+const includeBaseConfig = `boot_delay: 5
+emulators:
+  snes: {binary: snes9x}
+games:
+  - id: celeste
+    game_name: Celeste
+    game_path: /opt/celeste/Celeste
+    launch_method: direct
+    enabled: true
+  - id: portal
+    game_name: Portal
+    game_path: steam://rungameid/400
+    launch_method: steam
+    enabled: true
+`
+
+const includeMainConfig = `version: 3
+include: [shared/base.yaml]
+# This machine's schedule
+games:
+  - game_name: Celeste
+    schedules:
+      - days: [Sat]
+        start_time: "10:00"
+        end_time: "12:00"
+`
+
+// writeIncludeFiles writes files, named relative to dir.
+func writeIncludeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestConfigLayerPaths_Order(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"b.yaml":                 "",
+		"a.yaml":                 "",
+		"config.d/20-x.yaml":     "",
+		"config.d/10-y.yml":      "",
+		"config.d/.10-y.yml.swp": "",
+		"config.d/notes.txt":     "",
+	})
+	main := filepath.Join(dir, "config.yaml")
+
+	got := configLayerPaths(main, []string{"b.yaml", "*.yaml", "missing.yaml", "config.d/20-x.yaml", "config.yaml"})
+	want := []string{"b.yaml", "a.yaml", "missing.yaml", "config.d/20-x.yaml", "config.d/10-y.yml"}
+	for i := range want {
+		want[i] = filepath.Join(dir, want[i])
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("configLayerPaths() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestParseLayeredConfig_Merges(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"shared/base.yaml": includeBaseConfig,
+		"config.d/laptop.yaml": `version: 1
+include: [nowhere.yaml]
+boot_delay: 20
+emulators:
+  gba: {binary: mgba}
+games:
+  - game_name: Portal
+    enabled: false
+`,
+	})
+	main := filepath.Join(dir, "config.yaml")
+	cfg := defaultConfig()
+	changes, errs, ok, inc := parseLayeredConfig(main, []byte(includeMainConfig), cfg)
+	if !ok || len(errs) > 0 || len(changes) > 0 {
+		t.Fatalf("parseLayeredConfig() changes %q, errs %v, ok %v", changes, errs, ok)
+	}
+
+	if cfg.BootDelay != 20 || cfg.Version != 3 || !slices.Equal(cfg.Include, []string{"shared/base.yaml"}) {
+		t.Errorf("settings: boot_delay %d, version %d, include %q", cfg.BootDelay, cfg.Version, cfg.Include)
+	}
+	if len(cfg.Emulators) != 2 {
+		t.Errorf("emulators should merge by name, got %v", cfg.Emulators)
+	}
+	if len(cfg.Games) != 2 {
+		t.Fatalf("expected the two shared games, got %+v", cfg.Games)
+	}
+	celeste, portal := cfg.Games[0], cfg.Games[1]
+	if celeste.GamePath != "/opt/celeste/Celeste" || len(celeste.Schedules) != 1 || celeste.Schedules[0].Days[0] != "Sat" {
+		t.Errorf("config.yaml should add a schedule to the shared game: %+v", celeste)
+	}
+	if portal.Enabled || portal.LaunchMethod != "steam" {
+		t.Errorf("the drop-in should only disable Portal: %+v", portal)
+	}
+	if celeste.ID == "" || celeste.ID == portal.ID {
+		t.Errorf("shared games need distinct IDs, got %q and %q", celeste.ID, portal.ID)
+	}
+	if got := inc.GameFiles[celeste.ID]; got != filepath.Join(dir, "shared", "base.yaml") {
+		t.Errorf("Celeste should come from base.yaml, got %q", got)
+	}
+	if inc.Base.Games[0].Schedules != nil || inc.Base.BootDelay != 20 {
+		t.Errorf("Base should be the included files alone: %+v", inc.Base)
+	}
+
+	again := defaultConfig()
+	parseLayeredConfig(main, []byte(includeMainConfig), again)
+	if again.Games[0].ID != celeste.ID {
+		t.Error("IDs of included games must not change between loads")
+	}
+}
+
+func TestParseLayeredConfig_RejectsDirectoryWildcards(t *testing.T) {
+	dir := t.TempDir()
+	game := "games:\n  - id: celeste\n    game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    launch_method: direct\n"
+	writeIncludeFiles(t, dir, map[string]string{"shared/games.yaml": game})
+	main := filepath.Join(dir, "config.yaml")
+	data := []byte("version: 3\ninclude:\n  - \"*/games.yaml\"\n")
+
+	if paths := configLayerPaths(main, []string{"*/games.yaml"}); len(paths) != 0 {
+		t.Errorf("a pattern the watcher can't follow should not be merged, got %v", paths)
+	}
+	cfg := defaultConfig()
+	_, errs, ok, _ := parseLayeredConfig(main, data, cfg)
+	if !ok || len(errs) != 1 || errs[0].Path != "include[0]" || errs[0].Line != 3 || !strings.Contains(errs[0].Msg, "wildcard in a directory name") {
+		t.Errorf("expected the pattern to be reported at line 3, got ok=%v %v", ok, errs)
+	}
+	if len(cfg.Games) != 0 {
+		t.Errorf("expected nothing merged from the rejected pattern, got %+v", cfg.Games)
+	}
+}
+
+func TestParseLayeredConfig_IncludedGamesWithoutID(t *testing.T) {
+	dir := t.TempDir()
+	base := "games:\n  - game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    launch_method: direct\n"
+	writeIncludeFiles(t, dir, map[string]string{"shared/base.yaml": base})
+	main := filepath.Join(dir, "config.yaml")
+	data := []byte("version: 3\ninclude: [shared/base.yaml]\n")
+
+	cfg := defaultConfig()
+	_, errs, _, _ := parseLayeredConfig(main, data, cfg)
+	if len(errs) != 1 || errs[0].Path != "games[0].id" || !strings.HasPrefix(errs[0].Error(), "base.yaml: line 2") {
+		t.Errorf("a missing id should be reported in base.yaml, got %v", errs)
+	}
+	again := defaultConfig()
+	parseLayeredConfig(main, data, again)
+	if cfg.Games[0].ID == "" || again.Games[0].ID != cfg.Games[0].ID {
+		t.Error("the game should still load, with an ID that's the same every load")
+	}
+
+	// Once the shared file gives it an id, the override saved under the
+	// derived one still applies.
+	override := fmt.Sprintf("version: 3\ninclude: [shared/base.yaml]\ngames:\n  - id: %s\n    game_name: Celeste\n    enabled: false\n", cfg.Games[0].ID)
+	writeIncludeFiles(t, dir, map[string]string{"shared/base.yaml": strings.Replace(base, "  - game_name", "  - id: celeste\n    game_name", 1)})
+	cfg = defaultConfig()
+	_, errs, _, _ = parseLayeredConfig(main, []byte(override), cfg)
+	if len(errs) != 0 || len(cfg.Games) != 1 || cfg.Games[0].ID != "celeste" || cfg.Games[0].Enabled {
+		t.Errorf("the override should find Celeste by name: %+v, errs %v", cfg.Games, errs)
+	}
+
+	// One that matches nothing is reported rather than silently added.
+	renamed := strings.Replace(override, "game_name: Celeste", "game_name: Celeste Classic", 1)
+	cfg = defaultConfig()
+	_, errs, _, _ = parseLayeredConfig(main, []byte(renamed), cfg)
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	if !strings.Contains(strings.Join(msgs, "\n"), "line 4, column 5: games[1]: changes a game that isn't in the included files") {
+		t.Errorf("expected the orphaned override to be reported, got:\n%s", strings.Join(msgs, "\n"))
+	}
+}
+
+func TestParseLayeredConfig_ReportsFile(t *testing.T) {
+	dir := t.TempDir()
+	writeIncludeFiles(t, dir, map[string]string{
+		"shared/base.yaml":  "games:\n  - game_name: Celeste\n    launch_method: direct\n",
+		"config.d/bad.yaml": "games: [\n",
+	})
+	main := filepath.Join(dir, "config.yaml")
+	_, errs, ok, _ := parseLayeredConfig(main, []byte("version: 3\ninclude: [shared/base.yaml, gone.yaml]\n"), defaultConfig())
+	if !ok {
+		t.Fatal("problems in included files shouldn't stop config.yaml loading")
+	}
+	var msgs []string
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+	all := strings.Join(msgs, "\n")
+	for _, want := range []string{"base.yaml: line 2, column 5: games[0].game_path: is required", "gone.yaml: can't be read", "bad.yaml: line"} {
+		if !strings.Contains(all, want) {
+			t.Errorf("expected %q in:\n%s", want, all)
+		}
+	}
+}
+
+func TestSaveConfig_WritesOnlyOverridesForIncludedGames(t *testing.T) {
+	app, dir := newTestApp(t)
+	writeIncludeFiles(t, dir, map[string]string{
+		"shared/base.yaml": includeBaseConfig,
+		"config.yaml":      includeMainConfig,
+	})
+	app.loadConfig()
+	portal := app.currentConfig().Games[1]
+	if app.gameSourceFile(portal) == "" {
+		t.Fatal("Portal should be marked as coming from base.yaml")
+	}
+
+	app.updateGame(portal.key(), func(g *Game) { g.Enabled = false })
+	app.updateConfig(func(cfg *Config) {
+		cfg.Games = append(cfg.Games, Game{GameName: "Hades", GamePath: "/opt/hades", LaunchMethod: "direct", Enabled: true})
+	})
+
+	data, _ := os.ReadFile(app.configPath)
+	saved := string(data)
+	for _, want := range []string{"# This machine's schedule", "id: " + portal.ID, "enabled: false", "game_path: /opt/hades"} {
+		if !strings.Contains(saved, want) {
+			t.Errorf("expected %q in config.yaml:\n%s", want, saved)
+		}
+	}
+	for _, unwanted := range []string{"steam://rungameid/400", "boot_delay", "emulators"} {
+		if strings.Contains(saved, unwanted) {
+			t.Errorf("config.yaml shouldn't repeat %q from base.yaml:\n%s", unwanted, saved)
+		}
+	}
+	if base, _ := os.ReadFile(filepath.Join(dir, "shared", "base.yaml")); string(base) != includeBaseConfig {
+		t.Error("included files must not be written")
+	}
+
+	before := app.currentConfig()
+	app.loadConfig()
+	after := app.currentConfig()
+	if len(after.Games) != 3 || after.Games[1].Enabled || after.Games[1].ID != portal.ID || after.Games[2].GameName != "Hades" {
+		t.Errorf("reloading should give the same games:\nbefore %+v\nafter  %+v", before.Games, after.Games)
+	}
+}
+
+func TestSaveConfig_ClearedFieldsOfIncludedGamesStayCleared(t *testing.T) {
+	app, dir := newTestApp(t)
+	writeIncludeFiles(t, dir, map[string]string{
+		"shared/base.yaml": `games:
+  - id: celeste
+    game_name: Celeste
+    game_path: /opt/celeste/Celeste
+    launch_method: direct
+    working_dir: /opt/celeste
+    env: {FNA_FORCE_VULKAN: "1"}
+    wrappers: [gamemoderun]
+    hooks:
+      pre_launch:
+        - command: echo hi
+`,
+		"config.yaml": "version: 3\ninclude: [shared/base.yaml]\n",
+	})
+	app.loadConfig()
+	app.updateGame("celeste", func(g *Game) {
+		g.WorkingDir, g.Env, g.Wrappers, g.Hooks = "", nil, nil, Hooks{}
+	})
+
+	data, _ := os.ReadFile(app.configPath)
+	for _, want := range []string{`working_dir: ""`, "env: {}", "wrappers: []", "hooks: {}"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in config.yaml:\n%s", want, data)
+		}
+	}
+	app.loadConfig()
+	g := app.currentConfig().Games[0]
+	if g.WorkingDir != "" || len(g.Env) != 0 || len(g.Wrappers) != 0 || len(g.Hooks.PreLaunch) != 0 {
+		t.Errorf("cleared fields came back from base.yaml after reloading: %+v", g)
+	}
+}
+
+func TestReloadChangedConfig_IncludedFileChanged(t *testing.T) {
+	app, dir := newTestApp(t)
+	writeIncludeFiles(t, dir, map[string]string{
+		"shared/base.yaml": includeBaseConfig,
+		"config.yaml":      includeMainConfig,
+	})
+	app.loadConfig()
+	app.updateGame(app.currentConfig().Games[0].key(), func(g *Game) { g.Enabled = false })
+
+	app.reloadChangedConfig()
+	if app.currentConfig().BootDelay != 5 {
+		t.Fatal("nothing changed, so nothing should be reloaded")
+	}
+
+	writeIncludeFiles(t, dir, map[string]string{"shared/base.yaml": strings.Replace(includeBaseConfig, "boot_delay: 5", "boot_delay: 8", 1)})
+	app.reloadChangedConfig()
+	if app.currentConfig().BootDelay != 8 {
+		t.Error("a change to an included file should be reloaded")
+	}
+
+	writeIncludeFiles(t, dir, map[string]string{"config.d/late.yaml": "boot_delay: 9\n"})
+	app.reloadChangedConfig()
+	if app.currentConfig().BootDelay != 9 {
+		t.Error("a new drop-in should be reloaded")
+	}
+}
+
+func TestWatchConfigPaths_IncludedFilesAndDropIns(t *testing.T) {
+	dir := t.TempDir()
+	main := filepath.Join(dir, "config.yaml")
+	shared := filepath.Join(t.TempDir(), "base.yaml")
+	dropIns := filepath.Join(dir, configDropInDir)
+	os.WriteFile(main, []byte("boot_delay: 1\n"), 0644)
+	os.WriteFile(shared, []byte("boot_delay: 1\n"), 0644)
+
+	changes := make(chan struct{}, 16)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		paths := func() []string { return []string{main, shared, dropIns} }
+		watchConfigPaths(paths, testWatchDebounce, testWatchRetry, done, func() { changes <- struct{}{} })
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		close(done)
+		<-stopped
+	})
+	time.Sleep(testWatchRetry)
+
+	os.WriteFile(shared, []byte("boot_delay: 2\n"), 0644)
+	expectChange(t, changes, "included file in another directory")
+
+	os.Mkdir(dropIns, 0755)
+	expectChange(t, changes, "config.d created")
+	time.Sleep(2 * testWatchRetry) // the directory itself is watched from now on
+	os.WriteFile(filepath.Join(dropIns, "notes.txt"), []byte("x"), 0644)
+	expectNoChange(t, changes, "non-YAML file in config.d")
+	os.WriteFile(filepath.Join(dropIns, "extra.yaml"), []byte("boot_delay: 3\n"), 0644)
+	expectChange(t, changes, "new drop-in")
+}
+
+func TestWatchConfigPaths_NewFileMatchingInclude(t *testing.T) {
+	app, dir := newTestApp(t)
+	writeIncludeFiles(t, dir, map[string]string{
+		"config.yaml":   "version: 3\ninclude: [shared/*.yaml]\n",
+		"shared/a.yaml": "boot_delay: 1\n",
+	})
+	app.loadConfig()
+	pattern := filepath.Join(dir, "shared", "*.yaml")
+	if !slices.Contains(app.watchedConfigPaths(), pattern) {
+		t.Fatalf("the include pattern should be watched, got %q", app.watchedConfigPaths())
+	}
+
+	changes := make(chan struct{}, 16)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		watchConfigPaths(app.watchedConfigPaths, testWatchDebounce, testWatchRetry, done, func() { changes <- struct{}{} })
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		close(done)
+		<-stopped
+	})
+	time.Sleep(testWatchRetry)
+
+	os.WriteFile(filepath.Join(dir, "shared", "notes.txt"), []byte("x"), 0644)
+	expectNoChange(t, changes, "file not matching the include pattern")
+	os.WriteFile(filepath.Join(dir, "shared", "b.yaml"), []byte("boot_delay: 7\n"), 0644)
+	expectChange(t, changes, "new file matching the include pattern")
+	app.reloadChangedConfig()
+	if got := app.currentConfig().BootDelay; got != 7 {
+		t.Errorf("the new included file should be merged, boot_delay = %d", got)
+	}
+}
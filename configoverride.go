@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// settingOverride replaces a config setting for this run without changing
+// config.yaml.
+type settingOverride struct {
+	Key    string // the setting's YAML key, e.g. "boot_delay"
+	Value  string
+	Source string // e.g. "--boot-delay" or "FRICTIONLESS_BOOT_DELAY"
+}
+
+// overridableSettings returns the YAML keys of the number and on/off
+// settings that can be overridden, in Config order.
+func overridableSettings() []string {
+	var keys []string
+	t := reflect.TypeFor[Config]()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Name == "GamePath" {
+			break // the legacy single-game fields follow
+		}
+		key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if key != "version" && (f.Type.Kind() == reflect.Int || f.Type.Kind() == reflect.Bool) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// settingEnvVar and settingFlag name the overrides for key.
+func settingEnvVar(key string) string { return "FRICTIONLESS_" + strings.ToUpper(key) }
+func settingFlag(key string) string   { return strings.ReplaceAll(key, "_", "-") }
+
+// settingField returns cfg's field for the setting key.
+func settingField(cfg *Config, key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(cfg).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if k, _, _ := strings.Cut(v.Type().Field(i).Tag.Get("yaml"), ","); k == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// setSetting parses value into cfg's setting key.
+func setSetting(cfg *Config, key, value string) error {
+	field, ok := settingField(cfg, key)
+	if !ok || !slices.Contains(overridableSettings(), key) {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not true or false", key, value)
+		}
+		field.SetBool(b)
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a whole number", key, value)
+		}
+		field.SetInt(int64(n))
+	}
+	return nil
+}
+
+// settingFlagValue is a flag.Value for one setting's command-line override.
+type settingFlagValue struct {
+	key       string
+	overrides *[]settingOverride
+}
+
+func (f settingFlagValue) String() string { return "" }
+
+func (f settingFlagValue) Set(value string) error {
+	if err := setSetting(defaultConfig(), f.key, value); err != nil {
+		return err
+	}
+	*f.overrides = append(*f.overrides, settingOverride{Key: f.key, Value: value, Source: "--" + settingFlag(f.key)})
+	return nil
+}
+
+// IsBoolFlag lets on/off settings be given as a bare --start-clients.
+func (f settingFlagValue) IsBoolFlag() bool {
+	field, _ := settingField(defaultConfig(), f.key)
+	return field.Kind() == reflect.Bool
+}
+
+// registerSettingFlags adds a --<setting> flag to fs for each overridable
+// setting, recording the ones given in overrides.
+func registerSettingFlags(fs *flag.FlagSet, overrides *[]settingOverride) {
+	for _, key := range overridableSettings() {
+		fs.Var(settingFlagValue{key, overrides}, settingFlag(key), fmt.Sprintf("override the %s setting (also %s)", key, settingEnvVar(key)))
+	}
+}
+
+// envSettingOverrides returns the settings overridden by FRICTIONLESS_*
+// environment variables. Invalid values are logged and ignored.
+func envSettingOverrides(getenv func(string) string) []settingOverride {
+	var overrides []settingOverride
+	for _, key := range overridableSettings() {
+		name := settingEnvVar(key)
+		value := getenv(name)
+		if value == "" {
+			continue
+		}
+		if err := setSetting(defaultConfig(), key, value); err != nil {
+			log.Printf("Ignoring %s: %v", name, err)
+			continue
+		}
+		overrides = append(overrides, settingOverride{Key: key, Value: value, Source: name})
+	}
+	return overrides
+}
+
+// settingOverrides returns the environment's overrides followed by flags',
+// so a flag wins over the environment.
+func settingOverrides(flags []settingOverride) []settingOverride {
+	return append(envSettingOverrides(os.Getenv), flags...)
+}
+
+// withOverrides returns cfg with app.overrides applied, or cfg itself when
+// there are none.
+func (app *App) withOverrides(cfg *Config) *Config {
+	if len(app.overrides) == 0 || cfg == nil {
+		return cfg
+	}
+	out := cfg.clone()
+	for _, o := range app.overrides {
+		setSetting(out, o.Key, o.Value) // checked when the override was read
+	}
+	return out
+}
+
+// withoutOverrides returns cfg with each overridden setting put back to its
+// value in file, so saves keep what the config files say.
+func (app *App) withoutOverrides(cfg, file *Config) *Config {
+	if len(app.overrides) == 0 || cfg == nil || file == nil {
+		return cfg
+	}
+	out := cfg.clone()
+	for _, o := range app.overrides {
+		dst, _ := settingField(out, o.Key)
+		src, _ := settingField(file, o.Key)
+		dst.Set(src)
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Setting overrides
+// ============================================================================
+
+func TestOverridableSettings(t *testing.T) {
+	keys := overridableSettings()
+	for _, want := range []string{"boot_delay", "launch_retries", "start_clients", "client_settle_time"} {
+		if !slices.Contains(keys, want) {
+			t.Errorf("%s should be overridable, got %q", want, keys)
+		}
+	}
+	for _, unwanted := range []string{"version", "games", "game_path", "enabled", "emulators"} {
+		if slices.Contains(keys, unwanted) {
+			t.Errorf("%s must not be overridable", unwanted)
+		}
+	}
+}
+
+func TestSettingFlags(t *testing.T) {
+	var overrides []settingOverride
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	registerSettingFlags(fs, &overrides)
+
+	if err := fs.Parse([]string{"--boot-delay", "3", "--start-clients"}); err != nil {
+		t.Fatal(err)
+	}
+	want := []settingOverride{{"boot_delay", "3", "--boot-delay"}, {"start_clients", "true", "--start-clients"}}
+	if !slices.Equal(overrides, want) {
+		t.Errorf("overrides = %+v", overrides)
+	}
+	if err := fs.Parse([]string{"--boot-delay", "soon"}); err == nil || !strings.Contains(err.Error(), "whole number") {
+		t.Errorf("expected an invalid number to be refused, got %v", err)
+	}
+}
+
+func TestEnvSettingOverrides(t *testing.T) {
+	env := map[string]string{
+		"FRICTIONLESS_BOOT_DELAY":    "0",
+		"FRICTIONLESS_START_CLIENTS": "maybe", // ignored
+		"FRICTIONLESS_CONFIG":        "/somewhere.yaml",
+	}
+	got := envSettingOverrides(func(k string) string { return env[k] })
+	if !slices.Equal(got, []settingOverride{{"boot_delay", "0", "FRICTIONLESS_BOOT_DELAY"}}) {
+		t.Errorf("envSettingOverrides() = %+v", got)
+	}
+}
+
+func TestSettingOverrides_FlagBeatsEnvironment(t *testing.T) {
+	t.Setenv("FRICTIONLESS_BOOT_DELAY", "30")
+	app, _ := newTestApp(t)
+	app.overrides = settingOverrides([]settingOverride{{"boot_delay", "3", "--boot-delay"}})
+	app.setConfig(defaultConfig())
+	if got := app.currentConfig().BootDelay; got != 3 {
+		t.Errorf("expected the flag's boot_delay, got %d", got)
+	}
+}
+
+func TestSettingOverrides_NotSaved(t *testing.T) {
+	app, _ := newTestApp(t)
+	os.WriteFile(app.configPath, []byte("version: 3\n# keep me\nboot_delay: 20\ngames: []\n"), 0644)
+	app.overrides = []settingOverride{{"boot_delay", "0", "FRICTIONLESS_BOOT_DELAY"}, {"start_clients", "true", "--start-clients"}}
+	app.loadConfig()
+	if cfg := app.currentConfig(); cfg.BootDelay != 0 || !cfg.StartClients {
+		t.Fatalf("overrides not applied: %+v", cfg)
+	}
+
+	app.updateConfig(func(cfg *Config) {
+		cfg.Games = append(cfg.Games, Game{GameName: "Celeste", GamePath: "/opt/celeste", LaunchMethod: "direct"})
+	})
+	data, _ := os.ReadFile(app.configPath)
+	saved := string(data)
+	if !strings.Contains(saved, "boot_delay: 20") || strings.Contains(saved, "start_clients") || !strings.Contains(saved, "Celeste") {
+		t.Errorf("the file should keep its own settings and get the new game:\n%s", saved)
+	}
+	if cfg := app.currentConfig(); cfg.BootDelay != 0 || !cfg.StartClients {
+		t.Errorf("overrides should still apply after saving: %+v", cfg)
+	}
+}
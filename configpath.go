@@ -0,0 +1,180 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+const appDirName = "FrictionlessLauncher"
+
+// Where locateConfig found config.yaml.
+const (
+	configFromFlag     = "--config"
+	configFromEnv      = "FRICTIONLESS_CONFIG"
+	configFromBinary   = "next to the binary"
+	configFromPlatform = "default location"
+)
+
+// locateConfig returns the config file to use and where it came from: the
+// --config flag (flagPath), else $FRICTIONLESS_CONFIG, else a config.yaml
+// next to the executable exe (portable mode), else the platform's config
+// directory.
+func locateConfig(flagPath, exe string) (path, source string) {
+	if flagPath != "" {
+		return absPath(flagPath), configFromFlag
+	}
+	if env := os.Getenv("FRICTIONLESS_CONFIG"); env != "" {
+		return absPath(env), configFromEnv
+	}
+	local := filepath.Join(filepath.Dir(exe), "config.yaml")
+	if fileExists(local) {
+		return local, configFromBinary
+	}
+	return filepath.Join(platformConfigDir(), "config.yaml"), configFromPlatform
+}
+
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// ensureConfigDir creates path's directory. If the platform directory
+// can't be created it falls back to portable mode next to exe.
+func ensureConfigDir(path, source, exe string) string {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Printf("Warning: Could not create config directory %s: %v", dir, err)
+		if source == configFromPlatform {
+			return filepath.Join(filepath.Dir(exe), "config.yaml")
+		}
+	}
+	return path
+}
+
+// platformConfigDir is where config.yaml lives when it isn't given or
+// portable: %LOCALAPPDATA% on Windows, Application Support on macOS, and
+// $XDG_CONFIG_HOME (~/.config) elsewhere.
+func platformConfigDir() string {
+	switch runtime.GOOS {
+	case "windows":
+		return filepath.Join(os.Getenv("LOCALAPPDATA"), appDirName)
+	case "darwin":
+		home, _ := os.UserHomeDir()
+		return filepath.Join(home, "Library", "Application Support", appDirName)
+	default:
+		return filepath.Join(xdgDir("XDG_CONFIG_HOME", ".config"), appDirName)
+	}
+}
+
+// appLogDir is where the log, launch history and game output go: next to
+// the config on Windows and macOS, and $XDG_STATE_HOME (~/.local/state)
+// elsewhere.
+func appLogDir() string {
+	switch runtime.GOOS {
+	case "windows", "darwin":
+		return platformConfigDir()
+	default:
+		return filepath.Join(xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state")), appDirName)
+	}
+}
+
+// xdgDir returns the XDG base directory in env, or fallback under the home
+// directory when it's unset or, against the spec, relative.
+func xdgDir(env, fallback string) string {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, fallback)
+}
+
+// legacyLogDir is where earlier versions kept the log and history. Only
+// systems that now use appLogDir's XDG state directory moved.
+func legacyLogDir() string {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return appLogDir()
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", appDirName)
+}
+
+// moveLegacyState moves the launch history and game logs from the old
+// directory to the new one, unless the new one already has them, and
+// returns what it moved.
+func moveLegacyState(from, to string) []string {
+	if filepath.Clean(from) == filepath.Clean(to) {
+		return nil
+	}
+	var moved []string
+	for _, name := range []string{historyFileName, "games"} {
+		src, dst := filepath.Join(from, name), filepath.Join(to, name)
+		if !fileExists(src) || fileExists(dst) {
+			continue
+		}
+		if err := os.MkdirAll(to, 0755); err != nil {
+			log.Printf("Warning: Could not create %s: %v", to, err)
+			return moved
+		}
+		if err := os.Rename(src, dst); err != nil {
+			log.Printf("Warning: Could not move %s to %s: %v", src, to, err)
+			continue
+		}
+		moved = append(moved, name)
+	}
+	return moved
+}
+
+// printConfigPaths writes where the config, its included files and the logs
+// are, the active setting overrides, and how each can be changed; it's the
+// "config path" command.
+func (app *App) printConfigPaths(w io.Writer, source string) {
+	fmt.Fprintf(w, "Config file:  %s (%s)\n", app.configPath, source)
+	var head struct {
+		Include []string `yaml:"include"`
+	}
+	if data, err := os.ReadFile(app.configPath); err == nil {
+		yaml.Unmarshal(data, &head)
+	} else {
+		fmt.Fprintln(w, "              (doesn't exist yet; created on first start)")
+	}
+	for i, p := range configLayerPaths(app.configPath, head.Include) {
+		label := ""
+		if i == 0 {
+			label = "Merged with:"
+		}
+		fmt.Fprintf(w, "%-13s %s\n", label, p)
+	}
+	fmt.Fprintf(w, "Logs:         %s\n", appLogDir())
+	for i, o := range app.overrides {
+		label := ""
+		if i == 0 {
+			label = "Overrides:"
+		}
+		fmt.Fprintf(w, "%-13s %s = %s (%s)\n", label, o.Key, o.Value, o.Source)
+	}
+
+	fmt.Fprintf(w, "\nThe config file is the first of: --config, $FRICTIONLESS_CONFIG, a config.yaml next to the binary, %s.\n",
+		filepath.Join(platformConfigDir(), "config.yaml"))
+	fmt.Fprintln(w, "These settings can be overridden for a run without changing the file; the flag wins:")
+	for _, key := range overridableSettings() {
+		fmt.Fprintf(w, "  %-22s %-36s --%s\n", key, "$"+settingEnvVar(key), settingFlag(key))
+	}
+}
+
+// usage is the command line help.
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [flags] [config path]\n\n", filepath.Base(os.Args[0]))
+	fmt.Fprintln(out, "  config path   show where the config and logs are and how to override them")
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Config location
+// ============================================================================
+
+func TestLocateConfig_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	exe := filepath.Join(dir, "frictionless")
+	os.WriteFile(filepath.Join(dir, "config.yaml"), nil, 0644)
+	t.Setenv("FRICTIONLESS_CONFIG", filepath.Join(dir, "env.yaml"))
+
+	if path, source := locateConfig(filepath.Join(dir, "flag.yaml"), exe); path != filepath.Join(dir, "flag.yaml") || source != configFromFlag {
+		t.Errorf("the flag should win, got %s (%s)", path, source)
+	}
+	if path, source := locateConfig("", exe); path != filepath.Join(dir, "env.yaml") || source != configFromEnv {
+		t.Errorf("the environment should beat a portable config, got %s (%s)", path, source)
+	}
+	t.Setenv("FRICTIONLESS_CONFIG", "")
+	if path, source := locateConfig("", exe); path != filepath.Join(dir, "config.yaml") || source != configFromBinary {
+		t.Errorf("expected the portable config, got %s (%s)", path, source)
+	}
+	if path, source := locateConfig("", filepath.Join(t.TempDir(), "frictionless")); source != configFromPlatform || !strings.HasPrefix(path, platformConfigDir()) {
+		t.Errorf("expected the platform directory, got %s (%s)", path, source)
+	}
+}
+
+func TestLocateConfig_RelativeFlagIsMadeAbsolute(t *testing.T) {
+	path, _ := locateConfig("my.yaml", "/nowhere/frictionless")
+	if !filepath.IsAbs(path) || filepath.Base(path) != "my.yaml" {
+		t.Errorf("expected an absolute path, got %q", path)
+	}
+}
+
+func TestPlatformDirs_XDG(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		t.Skip("XDG directories are only used on Linux and other Unixes")
+	}
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	if got := platformConfigDir(); got != filepath.Join("/xdg/config", appDirName) {
+		t.Errorf("platformConfigDir() = %q", got)
+	}
+	if got := appLogDir(); got != filepath.Join("/xdg/state", appDirName) {
+		t.Errorf("appLogDir() = %q", got)
+	}
+
+	// Relative values are invalid per the spec and ignored.
+	t.Setenv("XDG_STATE_HOME", "state")
+	home, _ := os.UserHomeDir()
+	if got := appLogDir(); got != filepath.Join(home, ".local", "state", appDirName) {
+		t.Errorf("appLogDir() with a relative XDG_STATE_HOME = %q", got)
+	}
+}
+
+func TestMoveLegacyState(t *testing.T) {
+	from, to := t.TempDir(), filepath.Join(t.TempDir(), "state")
+	os.WriteFile(filepath.Join(from, historyFileName), []byte("{}\n"), 0644)
+	os.Mkdir(filepath.Join(from, "games"), 0755)
+	os.WriteFile(filepath.Join(from, "frictionless-launcher.log"), nil, 0644)
+
+	moved := moveLegacyState(from, to)
+	if len(moved) != 2 || !fileExists(filepath.Join(to, historyFileName)) || !fileExists(filepath.Join(to, "games")) {
+		t.Errorf("expected history and game logs moved, got %q", moved)
+	}
+	if !fileExists(filepath.Join(from, "frictionless-launcher.log")) {
+		t.Error("the old log file should be left alone")
+	}
+
+	os.WriteFile(filepath.Join(from, historyFileName), []byte("old\n"), 0644)
+	if moved := moveLegacyState(from, to); len(moved) != 0 {
+		t.Errorf("existing state must not be overwritten, moved %q", moved)
+	}
+}
+
+func TestPrintConfigPaths(t *testing.T) {
+	app, dir := newTestApp(t)
+	writeIncludeFiles(t, dir, map[string]string{
+		"config.yaml":     "include: [base.yaml]\n",
+		"base.yaml":       "",
+		"config.d/x.yaml": "",
+	})
+	app.overrides = []settingOverride{{Key: "boot_delay", Value: "3", Source: "--boot-delay"}}
+
+	var out bytes.Buffer
+	app.printConfigPaths(&out, configFromFlag)
+	got := out.String()
+	for _, want := range []string{
+		"Config file:  " + app.configPath + " (--config)",
+		"Merged with:  " + filepath.Join(dir, "base.yaml"),
+		filepath.Join(dir, "config.d", "x.yaml"),
+		"Logs:         " + appLogDir(),
+		"Overrides:    boot_delay = 3 (--boot-delay)",
+		"$FRICTIONLESS_BOOT_DELAY",
+		"--client-ready-timeout",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// configBackupCount is how many previous versions of config.yaml saves keep,
+// as config.yaml.bak.1 (newest) to config.yaml.bak.N.
+const configBackupCount = 5
+
+// configBackup is a saved copy of config.yaml that can be restored.
+type configBackup struct {
+	Path    string
+	ModTime time.Time
+}
+
+// writeConfigFile replaces config.yaml with data, first rotating the current
+// contents into the rolling backups. The watcher ignores the write.
+func (app *App) writeConfigFile(data []byte) error {
+	app.saveMu.Lock()
+	defer app.saveMu.Unlock()
+	return app.replaceConfigFile(data)
+}
+
+// replaceConfigFile is writeConfigFile for callers holding saveMu.
+func (app *App) replaceConfigFile(data []byte) error {
+	current, err := os.ReadFile(app.configPath)
+	if err == nil && bytes.Equal(current, data) {
+		return nil
+	}
+	if err == nil {
+		if err := rotateBackups(app.configPath, current, configBackupCount); err != nil {
+			log.Printf("Error backing up config: %v", err)
+		}
+	}
+	app.mu.Lock()
+	app.lastWrittenConfig = data
+	app.mu.Unlock()
+	return writeFileAtomic(app.configPath, data, 0644)
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place, so readers and crashes only ever see the old or new contents.
+// A symlinked path has its target replaced, so the link survives.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// rotateBackups shifts path.bak.1..n-1 up by one, dropping the oldest, and
+// stores data as path.bak.1.
+func rotateBackups(path string, data []byte, n int) error {
+	os.Remove(fmt.Sprintf("%s.bak.%d", path, n))
+	for i := n - 1; i >= 1; i-- {
+		from := fmt.Sprintf("%s.bak.%d", path, i)
+		if err := os.Rename(from, fmt.Sprintf("%s.bak.%d", path, i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return writeFileAtomic(path+".bak.1", data, 0644)
+}
+
+// configBackups lists the rolling and pre-migration backups of path, newest
+// first.
+func configBackups(path string) []configBackup {
+	rolling, _ := filepath.Glob(path + ".bak.*")
+	migration, _ := filepath.Glob(path + ".*.bak")
+	var backups []configBackup
+	for _, p := range append(rolling, migration...) {
+		info, err := os.Stat(p)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		backups = append(backups, configBackup{Path: p, ModTime: info.ModTime()})
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].ModTime.After(backups[j].ModTime) })
+	return backups
+}
+
+// restoreConfigBackup makes backup the active config.yaml and reloads it. The
+// replaced config becomes the newest backup, so a restore can be undone.
+func (app *App) restoreConfigBackup(backup string) error {
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		return err
+	}
+	cfg := defaultConfig()
+	changes, errs, ok := parseConfig(data, cfg)
+	if !ok {
+		return fmt.Errorf("%s can't be loaded: %v", filepath.Base(backup), errs[0])
+	}
+	// Upgrade an old backup here, so loading it doesn't save a second time
+	// and push the replaced config further down the backups.
+	if ids := assignGameIDs(cfg); len(changes) > 0 || len(ids) > 0 {
+		if data, err = marshalConfig(data, cfg); err != nil {
+			return err
+		}
+	}
+	if err := app.writeConfigFile(data); err != nil {
+		return err
+	}
+	log.Printf("Restored config from %s", backup)
+	app.loadConfig()
+	app.refreshTrayMenu()
+	return nil
+}
+
+// isOwnConfigWrite reports whether config.yaml still holds exactly what the
+// launcher last wrote, so a watcher event for it needs no reload.
+func (app *App) isOwnConfigWrite() bool {
+	app.mu.Lock()
+	written := app.lastWrittenConfig
+	app.mu.Unlock()
+	if written == nil {
+		return false
+	}
+	data, err := os.ReadFile(app.configPath)
+	return err == nil && bytes.Equal(data, written)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Config writes and backups
+// ============================================================================
+
+func TestWriteFileAtomic_ReplacesWithoutLeftovers(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("old"), 0644)
+
+	if err := writeFileAtomic(path, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "new" {
+		t.Errorf("file = %q, want new", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("expected only config.yaml to remain, got %v", entries)
+	}
+}
+
+func TestWriteFileAtomic_KeepsSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks needs extra privileges on Windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "config.yaml")
+	os.MkdirAll(filepath.Dir(target), 0755)
+	os.WriteFile(target, []byte("old"), 0644)
+	link := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Lstat(link); info.Mode()&os.ModeSymlink == 0 {
+		t.Error("the symlink was replaced by a regular file")
+	}
+	if data, _ := os.ReadFile(target); string(data) != "new" {
+		t.Errorf("target = %q, want new", data)
+	}
+}
+
+func TestWriteConfigFile_ConcurrentSavesKeepBackups(t *testing.T) {
+	app, _ := newTestApp(t)
+	const saves = 20
+	var wg sync.WaitGroup
+	for i := 0; i < saves; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := app.writeConfigFile([]byte(fmt.Sprintf("boot_delay: %d\n", i))); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	paths := []string{app.configPath}
+	for i := 1; i <= configBackupCount; i++ {
+		paths = append(paths, fmt.Sprintf("%s.bak.%d", app.configPath, i))
+	}
+	seen := map[string]bool{}
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil || seen[string(data)] {
+			t.Errorf("%s: interleaved saves lost or duplicated a version (%v)", filepath.Base(p), err)
+		}
+		seen[string(data)] = true
+	}
+}
+
+func TestSaveConfig_RollsBackups(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	for i := 0; i < configBackupCount+2; i++ {
+		app.config.BootDelay = i
+		app.saveConfig()
+	}
+
+	rolling, _ := filepath.Glob(app.configPath + ".bak.*")
+	if len(rolling) != configBackupCount {
+		t.Fatalf("expected %d backups, got %v", configBackupCount, rolling)
+	}
+	// .bak.1 holds the save before the last one, .bak.N the oldest kept.
+	for i, want := range map[int]int{1: configBackupCount, configBackupCount: 1} {
+		data, _ := os.ReadFile(fmt.Sprintf("%s.bak.%d", app.configPath, i))
+		if !strings.Contains(string(data), fmt.Sprintf("boot_delay: %d\n", want)) {
+			t.Errorf(".bak.%d should hold boot_delay %d, got:\n%s", i, want, data)
+		}
+	}
+}
+
+func TestSaveConfig_UnchangedDoesNotRotate(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.saveConfig()
+	app.saveConfig()
+	if rolling, _ := filepath.Glob(app.configPath + ".bak.*"); len(rolling) != 0 {
+		t.Errorf("an identical save should not create backups, got %v", rolling)
+	}
+}
+
+func TestIsOwnConfigWrite(t *testing.T) {
+	app, _ := newTestApp(t)
+	if app.isOwnConfigWrite() {
+		t.Error("nothing written yet")
+	}
+	app.config = defaultConfig()
+	app.saveConfig()
+	if !app.isOwnConfigWrite() {
+		t.Error("the launcher's own save should be recognised")
+	}
+	os.WriteFile(app.configPath, []byte("boot_delay: 3\n"), 0644)
+	if app.isOwnConfigWrite() {
+		t.Error("an external edit must be reloaded")
+	}
+}
+
+func TestConfigBackups_NewestFirst(t *testing.T) {
+	app, _ := newTestApp(t)
+	old := time.Now().Add(-time.Hour)
+	migration := app.configPath + ".20261018-150405.bak"
+	os.WriteFile(migration, []byte("games: []\n"), 0644)
+	os.Chtimes(migration, old, old)
+	os.WriteFile(app.configPath+".bak.1", []byte("games: []\n"), 0644)
+
+	backups := configBackups(app.configPath)
+	if len(backups) != 2 || backups[0].Path != app.configPath+".bak.1" || backups[1].Path != migration {
+		t.Errorf("unexpected backups %+v", backups)
+	}
+}
+
+func TestRestoreConfigBackup(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.config.Games = []Game{{GameName: "Celeste", GamePath: "/opt/celeste/Celeste", LaunchMethod: "direct"}}
+	app.saveConfig()
+	app.config.Games = nil
+	app.saveConfig()
+
+	if err := app.restoreConfigBackup(app.configPath + ".bak.1"); err != nil {
+		t.Fatal(err)
+	}
+	if len(app.config.Games) != 1 || app.config.Games[0].GameName != "Celeste" {
+		t.Errorf("expected the backup's game to be loaded, got %+v", app.config.Games)
+	}
+	// The replaced config is kept so the restore can be undone.
+	if data, _ := os.ReadFile(app.configPath + ".bak.1"); !strings.Contains(string(data), "games: []") {
+		t.Errorf("expected the replaced config as the newest backup, got:\n%s", data)
+	}
+}
+
+func TestRestoreConfigBackup_RejectsBrokenFile(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.saveConfig()
+	broken := app.configPath + ".bak.1"
+	os.WriteFile(broken, []byte("games: [unclosed\n"), 0644)
+
+	if err := app.restoreConfigBackup(broken); err == nil {
+		t.Error("expected a broken backup to be refused")
+	}
+	if data, _ := os.ReadFile(app.configPath); strings.Contains(string(data), "unclosed") {
+		t.Error("config.yaml must be left alone")
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// configReloadDebounce is how long the config must stay quiet after a
+	// change before it is reloaded; editors often write it in several steps.
+	configReloadDebounce = 300 * time.Millisecond
+	// configWatchRetry is how often a lost directory watch is re-established.
+	configWatchRetry = 5 * time.Second
+)
+
+func (app *App) watchConfigFile() {
+	log.Printf("Watching config file for changes: %s", app.configPath)
+	watchConfigPaths(app.watchedConfigPaths, configReloadDebounce, configWatchRetry, nil, app.reloadChangedConfig)
+}
+
+// watchedConfigPaths returns config.yaml, the files it includes, its
+// include: patterns, which a new or missing file may come to match, and the
+// config.d directory, where a new drop-in may appear.
+func (app *App) watchedConfigPaths() []string {
+	paths := []string{app.configPath}
+	if inc := app.includedFiles(); inc != nil {
+		paths = append(paths, inc.Files...)
+	}
+	dir := filepath.Dir(app.configPath)
+	if cfg := app.currentConfig(); cfg != nil {
+		for _, pattern := range cfg.Include {
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join(dir, pattern)
+			}
+			paths = append(paths, pattern)
+		}
+	}
+	return append(paths, filepath.Join(dir, configDropInDir))
+}
+
+// reloadChangedConfig reloads config.yaml after the watcher saw it or one of
+// its included files change, unless the change was the launcher's own save
+// or config.yaml is gone.
+func (app *App) reloadChangedConfig() {
+	if _, err := os.Stat(app.configPath); os.IsNotExist(err) {
+		log.Println("Config file was removed; keeping the current config until it comes back")
+		return
+	}
+	if app.isOwnConfigWrite() && app.includesUnchanged() {
+		return
+	}
+	log.Printf("Config file changed, reloading...")
+	app.loadConfig()
+	log.Println("Config reloaded successfully")
+	app.refreshTrayMenu()
+}
+
+// includesUnchanged reports whether the files merged into config.yaml are
+// as they were at the last load.
+func (app *App) includesUnchanged() bool {
+	cfg := app.currentConfig()
+	if cfg == nil {
+		return false
+	}
+	if inc := app.includedFiles(); inc != nil {
+		return inc.unchanged(app.configPath, cfg.Include)
+	}
+	return len(configLayerPaths(app.configPath, cfg.Include)) == 0
+}
+
+// watchConfigPaths calls onChange once any of the files paths returns, a
+// file matching one that is a glob pattern, or a config file inside one that
+// is a directory, has been written, created, replaced or removed and then
+// left alone for debounce. Patterns may only have wildcards in the file
+// name. It watches the files' directories rather than the files, so editors
+// that save by renaming a new file over the old one are seen, and re-adds a
+// watch every retry while its directory is missing. paths is asked again
+// after each onChange, as a reload may include other files. It returns when
+// done is closed.
+func watchConfigPaths(paths func() []string, debounce, retry time.Duration, done <-chan struct{}, onChange func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Printf("Error creating file watcher: %v", err)
+		return
+	}
+	defer watcher.Close()
+
+	var targets []string
+	watched := make(map[string]bool) // directories being watched
+	lost := make(map[string]bool)    // directories whose watch went away with them
+	// update watches the directories the targets are in, and the targets
+	// that are directories, and reports whether a lost one came back. A
+	// missing directory is expected while it's gone; only report other
+	// failures.
+	update := func() (regained bool) {
+		targets = targets[:0]
+		want := make(map[string]bool)
+		for _, p := range paths() {
+			p = filepath.Clean(p)
+			targets = append(targets, p)
+			want[filepath.Dir(p)] = true
+			if fi, err := os.Stat(p); err == nil && fi.IsDir() {
+				want[p] = true
+			}
+		}
+		for dir := range watched {
+			if !want[dir] {
+				watcher.Remove(dir)
+				delete(watched, dir)
+			}
+		}
+		for dir := range lost {
+			if !want[dir] {
+				delete(lost, dir)
+			}
+		}
+		for dir := range want {
+			if watched[dir] {
+				continue
+			}
+			if err := watcher.Add(dir); err != nil {
+				if !os.IsNotExist(err) {
+					log.Printf("Error watching config directory %s: %v", dir, err)
+				}
+				continue
+			}
+			watched[dir] = true
+			if lost[dir] {
+				log.Printf("Watching config directory %s again", dir)
+				delete(lost, dir)
+				regained = true
+			}
+		}
+		return regained
+	}
+	relevant := func(name string) bool {
+		for _, p := range targets {
+			if name == p || filepath.Dir(name) == p && isConfigDropIn(name) {
+				return true
+			}
+			if matched, _ := filepath.Match(p, name); matched {
+				return true
+			}
+		}
+		return false
+	}
+	update()
+
+	retryTicker := time.NewTicker(retry)
+	defer retryTicker.Stop()
+	var settle *time.Timer
+	var settled <-chan time.Time
+	changed := func() {
+		if settle == nil {
+			settle = time.NewTimer(debounce)
+		} else {
+			settle.Reset(debounce)
+		}
+		settled = settle.C
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			name := filepath.Clean(event.Name)
+			if watched[name] && event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				log.Printf("Config directory %s went away; watching for it to return", name)
+				watcher.Remove(name)
+				delete(watched, name)
+				lost[name] = true
+			}
+			if relevant(name) && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				changed()
+			}
+
+		case <-settled:
+			settled = nil
+			onChange()
+			if update() {
+				changed()
+			}
+
+		case <-retryTicker.C:
+			if update() {
+				changed() // files may have come back with it
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("Watcher error: %v", err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Config file watching
+// ============================================================================
+
+const (
+	testWatchDebounce = 50 * time.Millisecond
+	testWatchRetry    = 20 * time.Millisecond
+)
+
+// startConfigWatch watches path until the test ends and returns a channel
+// that receives each onChange call.
+func startConfigWatch(t *testing.T, path string) <-chan struct{} {
+	t.Helper()
+	changes := make(chan struct{}, 16)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		watchConfigPaths(func() []string { return []string{path} }, testWatchDebounce, testWatchRetry, done, func() { changes <- struct{}{} })
+		close(stopped)
+	}()
+	t.Cleanup(func() {
+		close(done)
+		<-stopped
+	})
+	time.Sleep(testWatchRetry) // let the watch be set up
+	return changes
+}
+
+func expectChange(t *testing.T, changes <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatalf("%s: no reload", what)
+	}
+}
+
+func expectNoChange(t *testing.T, changes <-chan struct{}, what string) {
+	t.Helper()
+	select {
+	case <-changes:
+		t.Fatalf("%s: unexpected reload", what)
+	case <-time.After(4 * testWatchDebounce):
+	}
+}
+
+func TestWatchConfigPath_InPlaceWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("boot_delay: 1\n"), 0644)
+	changes := startConfigWatch(t, path)
+
+	os.WriteFile(path, []byte("boot_delay: 2\n"), 0644)
+	expectChange(t, changes, "write")
+}
+
+func TestWatchConfigPath_RenameOverSurvives(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("boot_delay: 1\n"), 0644)
+	changes := startConfigWatch(t, path)
+
+	// Editors like vim save by writing a new file and renaming it over the
+	// old one; the watch must keep working afterwards.
+	for i, content := range []string{"boot_delay: 2\n", "boot_delay: 3\n"} {
+		tmp := filepath.Join(dir, ".config.yaml.swp")
+		os.WriteFile(tmp, []byte(content), 0644)
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+		expectChange(t, changes, fmt.Sprintf("rename save %d", i+1))
+	}
+}
+
+func TestWatchConfigPath_RemoveThenCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("boot_delay: 1\n"), 0644)
+	changes := startConfigWatch(t, path)
+
+	os.Remove(path)
+	expectChange(t, changes, "remove")
+	os.WriteFile(path, []byte("boot_delay: 2\n"), 0644)
+	expectChange(t, changes, "create")
+}
+
+func TestWatchConfigPath_DebouncesBursts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	os.WriteFile(path, []byte("boot_delay: 1\n"), 0644)
+	changes := startConfigWatch(t, path)
+
+	for i := 0; i < 5; i++ {
+		os.WriteFile(path, []byte("boot_delay: 2\n"), 0644)
+		time.Sleep(testWatchDebounce / 5)
+	}
+	expectChange(t, changes, "burst")
+	expectNoChange(t, changes, "burst")
+}
+
+func TestWatchConfigPath_IgnoresOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("boot_delay: 1\n"), 0644)
+	changes := startConfigWatch(t, path)
+
+	os.WriteFile(filepath.Join(dir, "config.yaml.bak.1"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0644)
+	expectNoChange(t, changes, "sibling files")
+}
+
+func TestWatchConfigPath_DirectoryRecreated(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "FrictionlessLauncher")
+	os.Mkdir(dir, 0755)
+	path := filepath.Join(dir, "config.yaml")
+	os.WriteFile(path, []byte("boot_delay: 1\n"), 0644)
+	changes := startConfigWatch(t, path)
+
+	os.RemoveAll(dir)
+	expectChange(t, changes, "directory removed")
+	os.Mkdir(dir, 0755)
+	os.WriteFile(path, []byte("boot_delay: 2\n"), 0644)
+	expectChange(t, changes, "directory recreated")
+
+	// Drain anything left from the recreation, then check the new watch.
+	time.Sleep(4 * testWatchDebounce)
+	for len(changes) > 0 {
+		<-changes
+	}
+	os.WriteFile(path, []byte("boot_delay: 3\n"), 0644)
+	expectChange(t, changes, "write after re-watch")
+}
+
+func TestReloadChangedConfig_SkipsOwnWritesAndRemoval(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.config.BootDelay = 7
+	app.saveConfig()
+
+	app.config.BootDelay = 99 // would be reset by a reload
+	app.reloadChangedConfig()
+	if app.config.BootDelay != 99 {
+		t.Error("the launcher's own save must not be reloaded")
+	}
+
+	os.WriteFile(app.configPath, []byte("boot_delay: 3\n"), 0644)
+	app.reloadChangedConfig()
+	if app.config.BootDelay != 3 {
+		t.Errorf("expected an external edit to be reloaded, got boot_delay %d", app.config.BootDelay)
+	}
+
+	os.Remove(app.configPath)
+	app.reloadChangedConfig()
+	if _, err := os.Stat(app.configPath); !os.IsNotExist(err) {
+		t.Error("a removed config must not be recreated with defaults")
+	}
+	if app.config.BootDelay != 3 {
+		t.Error("the current config should be kept when the file is removed")
+	}
+}
@@ -323,24 +323,37 @@ func TestAppLogDir_PlatformSpecific(t *testing.T) {
 	case "windows":
 		// LOCALAPPDATA is set by the OS; just verify FrictionlessLauncher is present
 	default:
-		if !strings.Contains(dir, ".config") {
-			t.Errorf("Linux log dir should contain .config, got %q", dir)
+		want := os.Getenv("XDG_STATE_HOME")
+		if !filepath.IsAbs(want) {
+			home, _ := os.UserHomeDir()
+			want = filepath.Join(home, ".local", "state")
+		}
+		if !strings.HasPrefix(dir, want) {
+			t.Errorf("Linux log dir should be under %q, got %q", want, dir)
 		}
 	}
 }
 
 // ============================================================================
-// getConfigPath
+// locateConfig / ensureConfigDir
 // ============================================================================
 
-func TestGetConfigPath_ReturnsConfigYAML(t *testing.T) {
-	p := getConfigPath()
+// startupConfigPath resolves the config path the way main does without
+// --config.
+func startupConfigPath() string {
+	exe, _ := os.Executable()
+	path, source := locateConfig("", exe)
+	return ensureConfigDir(path, source, exe)
+}
+
+func TestLocateConfig_ReturnsConfigYAML(t *testing.T) {
+	p := startupConfigPath()
 	if !strings.HasSuffix(p, "config.yaml") {
-		t.Errorf("getConfigPath should end with config.yaml, got %q", p)
+		t.Errorf("the config path should end with config.yaml, got %q", p)
 	}
 }
 
-func TestGetConfigPath_LocalWins(t *testing.T) {
+func TestLocateConfig_LocalWins(t *testing.T) {
 	exe, err := os.Executable()
 	if err != nil {
 		t.Skip("cannot determine executable path")
@@ -353,7 +366,7 @@ func TestGetConfigPath_LocalWins(t *testing.T) {
 	f.Close()
 	defer os.Remove(localCfg)
 
-	got := getConfigPath()
+	got := startupConfigPath()
 	if got != localCfg {
 		t.Errorf("expected local config %q, got %q", localCfg, got)
 	}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// desktopEntry holds the [Desktop Entry] keys the launcher cares about.
+type desktopEntry struct {
+	Name       string
+	Exec       string
+	TryExec    string
+	Path       string
+	Categories []string
+	Type       string
+	NoDisplay  bool
+	Hidden     bool
+}
+
+// parseDesktopEntry reads the main group of a freedesktop .desktop file.
+// Localised keys such as Name[de] are ignored in favour of the default.
+func parseDesktopEntry(path string) (desktopEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return desktopEntry{}, err
+	}
+	defer f.Close()
+
+	var entry desktopEntry
+	inMain := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			inMain = line == "[Desktop Entry]"
+			continue
+		}
+		if !inMain {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case "Name":
+			entry.Name = value
+		case "Exec":
+			entry.Exec = value
+		case "TryExec":
+			entry.TryExec = value
+		case "Path":
+			entry.Path = value
+		case "Type":
+			entry.Type = value
+		case "Categories":
+			for _, c := range strings.Split(value, ";") {
+				if c = strings.TrimSpace(c); c != "" {
+					entry.Categories = append(entry.Categories, c)
+				}
+			}
+		case "NoDisplay":
+			entry.NoDisplay = value == "true"
+		case "Hidden":
+			entry.Hidden = value == "true"
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return desktopEntry{}, err
+	}
+	if entry.Exec == "" {
+		return desktopEntry{}, errors.New("no Exec key in [Desktop Entry]")
+	}
+	return entry, nil
+}
+
+// isGame reports whether the entry is a visible application in the Game
+// category.
+func (e desktopEntry) isGame() bool {
+	if e.Type != "Application" || e.NoDisplay || e.Hidden {
+		return false
+	}
+	for _, c := range e.Categories {
+		if c == "Game" {
+			return true
+		}
+	}
+	return false
+}
+
+// desktopExecArgs splits an Exec value into argv, dropping field codes such as
+// %f, %U and %i that only make sense when a file manager launches the entry.
+// Exec quoting follows the same double-quote rules as the shell.
+func desktopExecArgs(execLine string) ([]string, error) {
+	args, err := splitArgsFor(execLine, "linux")
+	if err != nil {
+		return nil, err
+	}
+	var argv []string
+	for _, arg := range args {
+		if len(arg) == 2 && arg[0] == '%' && arg[1] != '%' {
+			continue
+		}
+		argv = append(argv, strings.ReplaceAll(arg, "%%", "%"))
+	}
+	if len(argv) == 0 {
+		return nil, errors.New("empty Exec line")
+	}
+	return argv, nil
+}
+
+// desktopLaunchCmd builds the command for the desktop launch method, where
+// GamePath is the .desktop file. The entry's Path key is used as the working
+// directory unless the game sets its own.
+func desktopLaunchCmd(game Game) *exec.Cmd {
+	entry, err := parseDesktopEntry(game.GamePath)
+	var program []string
+	if err == nil {
+		program, err = desktopExecArgs(entry.Exec)
+	}
+	if err != nil {
+		// Let Start report the problem rather than running something else.
+		cmd := exec.Command(game.GamePath)
+		cmd.Err = fmt.Errorf("desktop entry %s: %w", game.GamePath, err)
+		return cmd
+	}
+	cmd := execGameCmd(game, program)
+	cmd.Env = gameEnv(game.Env)
+	cmd.Dir = game.WorkingDir
+	if cmd.Dir == "" {
+		cmd.Dir = entry.Path
+	}
+	return cmd
+}
+
+// xdgApplicationDirs returns the XDG application directories in priority
+// order: $XDG_DATA_HOME first, then each of $XDG_DATA_DIRS.
+func xdgApplicationDirs() []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, _ := os.UserHomeDir()
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	dirs := []string{filepath.Join(dataHome, "applications")}
+	for _, d := range filepath.SplitList(dataDirs) {
+		if d != "" {
+			dirs = append(dirs, filepath.Join(d, "applications"))
+		}
+	}
+	return dirs
+}
+
+func discoverDesktopGames() []DiscoveredGame {
+	return discoverDesktopGamesFrom(xdgApplicationDirs())
+}
+
+// discoverDesktopGamesFrom lists game entries under the given application
+// directories. An entry ID found in an earlier directory shadows later ones,
+// as the XDG spec requires, so a user's hidden override suppresses the
+// system entry.
+func discoverDesktopGamesFrom(dirs []string) []DiscoveredGame {
+	seen := map[string]bool{}
+	var games []DiscoveredGame
+	for _, dir := range dirs {
+		filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".desktop") {
+				return nil
+			}
+			rel, _ := filepath.Rel(dir, path)
+			id := strings.ReplaceAll(rel, string(filepath.Separator), "-")
+			if seen[id] {
+				return nil
+			}
+			seen[id] = true
+
+			entry, err := parseDesktopEntry(path)
+			if err != nil || !entry.isGame() || entry.Name == "" {
+				return nil
+			}
+			// Steam writes shortcuts for its games; those are already
+			// discovered (with cloud saves) through the steam method.
+			if strings.Contains(entry.Exec, "steam://rungameid/") {
+				return nil
+			}
+			if entry.TryExec != "" {
+				if _, err := exec.LookPath(entry.TryExec); err != nil {
+					return nil
+				}
+			}
+			games = append(games, DiscoveredGame{
+				Name:         entry.Name,
+				LaunchMethod: "desktop",
+				GamePath:     path,
+			})
+			return nil
+		})
+	}
+	return games
+}
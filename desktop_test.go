@@ -0,0 +1,125 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeDesktopFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+const sampleDesktopEntry = `[Desktop Entry]
+Type=Application
+Name=SuperTuxKart
+Name[de]=SuperTuxKart DE
+Exec=supertuxkart %U --fullscreen "--name=A B"
+Path=/opt/stk
+Categories=Game;ArcadeGame;
+
+[Desktop Action Windowed]
+Exec=supertuxkart --windowed
+`
+
+func TestParseDesktopEntry(t *testing.T) {
+	path := writeDesktopFile(t, t.TempDir(), "stk.desktop", sampleDesktopEntry)
+	entry, err := parseDesktopEntry(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.Name != "SuperTuxKart" {
+		t.Errorf("Name = %q, localised keys should be ignored", entry.Name)
+	}
+	if entry.Exec != `supertuxkart %U --fullscreen "--name=A B"` {
+		t.Errorf("Exec = %q, action groups should not override the main group", entry.Exec)
+	}
+	if entry.Path != "/opt/stk" || !entry.isGame() {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+}
+
+func TestParseDesktopEntry_NoExec(t *testing.T) {
+	path := writeDesktopFile(t, t.TempDir(), "x.desktop", "[Desktop Entry]\nName=X\n")
+	if _, err := parseDesktopEntry(path); err == nil {
+		t.Error("expected error for entry without Exec")
+	}
+}
+
+func TestDesktopExecArgs(t *testing.T) {
+	got, err := desktopExecArgs(`env DXVK_HUD=1 game %f --rate 100%% %i`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "|") != "env|DXVK_HUD=1|game|--rate|100%" {
+		t.Errorf("desktopExecArgs() = %q", got)
+	}
+	if _, err := desktopExecArgs("%U"); err == nil {
+		t.Error("expected error for Exec with only field codes")
+	}
+}
+
+func TestBuildLaunchCmd_Desktop(t *testing.T) {
+	path := writeDesktopFile(t, t.TempDir(), "stk.desktop", sampleDesktopEntry)
+	game := Game{GamePath: path, LaunchMethod: "desktop", LaunchArgs: "--track=lighthouse", Wrappers: []string{"gamemoderun"}}
+	cmd := buildLaunchCmd(game, "linux")
+	want := "gamemoderun|supertuxkart|--fullscreen|--name=A B|--track=lighthouse"
+	if got := strings.Join(cmd.Args, "|"); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if cmd.Dir != "/opt/stk" {
+		t.Errorf("expected the entry's Path as working dir, got %q", cmd.Dir)
+	}
+}
+
+func TestBuildLaunchCmd_DesktopMissingFileFailsOnStart(t *testing.T) {
+	cmd := buildLaunchCmd(Game{GamePath: "/does/not/exist.desktop", LaunchMethod: "desktop"}, "linux")
+	err := cmd.Start()
+	if err == nil || !strings.Contains(err.Error(), "desktop entry") {
+		t.Errorf("expected desktop entry error from Start, got %v", err)
+	}
+}
+
+func TestDiscoverDesktopGamesFrom(t *testing.T) {
+	user := t.TempDir()
+	system := t.TempDir()
+
+	writeDesktopFile(t, system, "stk.desktop", sampleDesktopEntry)
+	writeDesktopFile(t, system, "editor.desktop", "[Desktop Entry]\nType=Application\nName=Editor\nExec=edit\nCategories=Utility;\n")
+	writeDesktopFile(t, system, "hidden.desktop", "[Desktop Entry]\nType=Application\nName=Hidden\nExec=h\nCategories=Game;\n")
+	writeDesktopFile(t, user, "hidden.desktop", "[Desktop Entry]\nType=Application\nName=Hidden\nExec=h\nCategories=Game;\nHidden=true\n")
+	writeDesktopFile(t, system, "steam-game.desktop", "[Desktop Entry]\nType=Application\nName=Portal\nExec=steam steam://rungameid/400\nCategories=Game;\n")
+	writeDesktopFile(t, system, "tryexec.desktop", "[Desktop Entry]\nType=Application\nName=Gone\nExec=gone\nTryExec=definitely-not-installed-xyz\nCategories=Game;\n")
+	writeDesktopFile(t, user, "itch/celeste.desktop", "[Desktop Entry]\nType=Application\nName=Celeste\nExec=/home/me/Games/Celeste/Celeste\nCategories=Game;\n")
+
+	games := discoverDesktopGamesFrom([]string{user, system, filepath.Join(t.TempDir(), "missing")})
+	var names []string
+	for _, g := range games {
+		if g.LaunchMethod != "desktop" {
+			t.Errorf("%s: expected desktop launch method, got %q", g.Name, g.LaunchMethod)
+		}
+		names = append(names, g.Name)
+	}
+	if strings.Join(names, ",") != "Celeste,SuperTuxKart" {
+		t.Errorf("discovered %q, want Celeste and SuperTuxKart only", names)
+	}
+}
+
+func TestXDGApplicationDirs(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/home/me/data")
+	t.Setenv("XDG_DATA_DIRS", "/usr/share:/var/lib/flatpak/exports/share")
+	got := xdgApplicationDirs()
+	want := []string{"/home/me/data/applications", "/usr/share/applications", "/var/lib/flatpak/exports/share/applications"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("xdgApplicationDirs() = %q, want %q", got, want)
+	}
+}
@@ -19,6 +19,12 @@ func discoverGames() []DiscoveredGame {
 	var games []DiscoveredGame
 	games = append(games, discoverSteamGames()...)
 	games = append(games, discoverEpicGames()...)
+	games = append(games, discoverBattlenetGames()...)
+	games = append(games, discoverUbisoftGames()...)
+	games = append(games, discoverItchGames()...)
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		games = append(games, discoverDesktopGames()...)
+	}
 	return games
 }
 
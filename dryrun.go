@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// launchPlan is what launching a game would do right now, worked out without
+// starting anything: the scheduler's decision and the fully resolved command.
+type launchPlan struct {
+	Game     Game
+	Launch   bool   // whether the scheduler would launch it now
+	Reason   string // why it would or would not
+	Command  []string
+	Env      []string // variables added to the launcher's own environment
+	Dir      string
+	Hooks    []string // hook commands in the order they would run
+	Warnings []string
+	Err      error // the launch could not be started even if due
+}
+
+// planLaunch runs the scheduler's and launcher's decision path for game at
+// now — schedule checks, global launch settings, wrappers, hooks and
+// buildLaunchCmd — without running hooks or starting the game.
+func (app *App) planLaunch(game Game, now time.Time) launchPlan {
+	plan := launchPlan{Game: game}
+	switch {
+	case !game.Enabled:
+		plan.Reason = "disabled"
+	case app.isGameRunning():
+		plan.Reason = "a game is already running"
+	case app.hasLaunchedInCurrentWindowAt(game, now):
+		plan.Reason = "already launched in the current schedule window"
+	case !app.isInScheduleWindowAt(game, now):
+		plan.Reason = "outside its schedule (next: " + app.nextScheduleLabelAt(game, now) + ")"
+	default:
+		plan.Launch = true
+		plan.Reason = "inside a schedule window"
+	}
+	if game.GamePath == "" {
+		plan.Err = fmt.Errorf("no game path configured")
+		return plan
+	}
+
+	game = app.resolveLaunch(game)
+	for _, warning := range []string{launchArgsWarning(game), launchEnvWarning(game), launchWrappersNote(game)} {
+		if warning != "" {
+			plan.Warnings = append(plan.Warnings, warning)
+		}
+	}
+	if client, ok := platformClients[game.LaunchMethod]; ok && !app.isPlatformRunning(game.LaunchMethod) {
+		if app.currentConfig().StartClients {
+			plan.Warnings = append(plan.Warnings, client.Name+" is not running and would be started first")
+		} else {
+			plan.Warnings = append(plan.Warnings, client.Name+" is not running — it will launch first, adding delay")
+		}
+	}
+	for _, h := range game.Hooks.PreLaunch {
+		plan.Hooks = append(plan.Hooks, hookPreLaunch+": "+h.Command)
+	}
+	for _, h := range game.Hooks.PostSession {
+		plan.Hooks = append(plan.Hooks, hookPostSession+": "+h.Command)
+	}
+	if err := checkWrappers(game); err != nil {
+		plan.Err = err
+	}
+
+	cmd := buildLaunchCmd(game, runtime.GOOS)
+	if cmd.Err != nil && plan.Err == nil {
+		plan.Err = cmd.Err
+	}
+	plan.Command = cmd.Args
+	plan.Dir = cmd.Dir
+	plan.Env = addedEnv(cmd.Env, os.Environ())
+	return plan
+}
+
+// addedEnv returns the entries of env that aren't inherited from base.
+func addedEnv(env, base []string) []string {
+	inherited := make(map[string]bool, len(base))
+	for _, kv := range base {
+		inherited[kv] = true
+	}
+	var added []string
+	for _, kv := range env {
+		if !inherited[kv] {
+			added = append(added, kv)
+		}
+	}
+	return added
+}
+
+// String renders the plan for the --dry-run output and the Test launch dialog.
+func (plan launchPlan) String() string {
+	var b strings.Builder
+	verdict := "would not launch"
+	switch {
+	case plan.Launch && plan.Err != nil:
+		verdict = "would fail to launch"
+	case plan.Launch:
+		verdict = "would launch"
+	}
+	fmt.Fprintf(&b, "%s (%s): %s — %s\n", plan.Game.GameName, plan.Game.LaunchMethod, verdict, plan.Reason)
+	if plan.Err != nil {
+		fmt.Fprintf(&b, "  error:   %v\n", plan.Err)
+	}
+	if len(plan.Command) > 0 {
+		fmt.Fprintf(&b, "  command: %s\n", quoteArgs(plan.Command))
+	}
+	dir := plan.Dir
+	if dir == "" {
+		dir = "(launcher's directory)"
+	}
+	fmt.Fprintf(&b, "  dir:     %s\n", dir)
+	for _, kv := range plan.Env {
+		fmt.Fprintf(&b, "  env:     %s\n", kv)
+	}
+	for _, h := range plan.Hooks {
+		fmt.Fprintf(&b, "  hook:    %s\n", h)
+	}
+	for _, w := range plan.Warnings {
+		fmt.Fprintf(&b, "  warning: %s\n", w)
+	}
+	return b.String()
+}
+
+// quoteArgs joins args into a shell command line, single-quoting any that
+// splitArgs would otherwise break apart.
+func quoteArgs(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a == "" || strings.ContainsAny(a, " \t\n'\"\\$`") {
+			a = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+		quoted[i] = a
+	}
+	return strings.Join(quoted, " ")
+}
+
+// dryRun prints the config's problems, if any, and the launch plan for
+// every configured game.
+func (app *App) dryRun(w io.Writer) {
+	if errs, rejected := app.configProblems(); rejected {
+		fmt.Fprintln(w, "config.yaml could not be loaded; the plan below uses the defaults:")
+		for _, e := range errs {
+			fmt.Fprintf(w, "  %v\n", e)
+		}
+	} else if len(errs) > 0 {
+		fmt.Fprintf(w, "config.yaml has %d problem(s):\n", len(errs))
+		for _, e := range errs {
+			fmt.Fprintf(w, "  %v\n", e)
+		}
+	}
+	now := time.Now()
+	games := app.currentConfig().Games
+	if len(games) == 0 {
+		fmt.Fprintln(w, "No games configured")
+		return
+	}
+	for _, game := range games {
+		fmt.Fprint(w, app.planLaunch(game, now))
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2"
+	fynetest "fyne.io/fyne/v2/test"
+	"fyne.io/fyne/v2/widget"
+)
+
+// ============================================================================
+// Dry run / Test launch
+// ============================================================================
+
+func dryRunGame(now time.Time) Game {
+	return Game{
+		GameName:     "Celeste",
+		GamePath:     "/opt/celeste/Celeste",
+		LaunchMethod: "direct",
+		LaunchArgs:   `--profile "Player One"`,
+		Env:          map[string]string{"FNA_FORCE_VULKAN": "1"},
+		WorkingDir:   "/opt/celeste",
+		Enabled:      true,
+		Schedules: []Schedule{
+			{Days: []string{now.Weekday().String()[:3]}, StartTime: "19:00", EndTime: "21:00"},
+		},
+	}
+}
+
+func TestPlanLaunch_ResolvesCommand(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local)
+	game := dryRunGame(now)
+	app := appWithGames([]Game{game})
+	app.config.Hooks = Hooks{PreLaunch: []Hook{{Command: "echo before"}}}
+
+	plan := app.planLaunch(game, now)
+
+	if !plan.Launch || plan.Reason != "inside a schedule window" {
+		t.Errorf("expected a launch inside the window, got %v %q", plan.Launch, plan.Reason)
+	}
+	if plan.Err != nil {
+		t.Errorf("unexpected error: %v", plan.Err)
+	}
+	want := []string{"/opt/celeste/Celeste", "--profile", "Player One"}
+	if strings.Join(plan.Command, "|") != strings.Join(want, "|") {
+		t.Errorf("Command = %q, want %q", plan.Command, want)
+	}
+	if plan.Dir != "/opt/celeste" {
+		t.Errorf("Dir = %q", plan.Dir)
+	}
+	if len(plan.Env) != 1 || plan.Env[0] != "FNA_FORCE_VULKAN=1" {
+		t.Errorf("Env = %q, want only the game's own variable", plan.Env)
+	}
+	if len(plan.Hooks) != 1 || plan.Hooks[0] != "pre_launch: echo before" {
+		t.Errorf("Hooks = %q", plan.Hooks)
+	}
+
+	out := plan.String()
+	for _, s := range []string{"Celeste (direct): would launch", `'Player One'`, "env:     FNA_FORCE_VULKAN=1"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("String() missing %q:\n%s", s, out)
+		}
+	}
+}
+
+func TestPlanLaunch_Reasons(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local)
+
+	disabled := dryRunGame(now)
+	disabled.Enabled = false
+	if plan := appWithGames(nil).planLaunch(disabled, now); plan.Launch || plan.Reason != "disabled" {
+		t.Errorf("disabled: got %v %q", plan.Launch, plan.Reason)
+	}
+
+	later := now.Add(2 * time.Hour)
+	if plan := appWithGames(nil).planLaunch(dryRunGame(now), later); plan.Launch || !strings.HasPrefix(plan.Reason, "outside its schedule") {
+		t.Errorf("outside window: got %v %q", plan.Launch, plan.Reason)
+	}
+
+	app := appWithGames(nil)
+	app.lastLaunchTime["Celeste"] = now.Add(-30 * time.Minute)
+	if plan := app.planLaunch(dryRunGame(now), now); plan.Launch || !strings.Contains(plan.Reason, "already launched") {
+		t.Errorf("already launched: got %v %q", plan.Launch, plan.Reason)
+	}
+}
+
+func TestPlanLaunch_ReportsErrorsWithoutStarting(t *testing.T) {
+	now := time.Date(2026, 10, 19, 20, 0, 0, 0, time.Local)
+	game := dryRunGame(now)
+	game.Wrappers = []string{"definitely-not-a-real-wrapper-xyz"}
+	app := appWithGames(nil)
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+
+	plan := app.planLaunch(game, now)
+
+	if plan.Err == nil || !strings.Contains(plan.String(), "would fail to launch") {
+		t.Errorf("expected a wrapper error, got:\n%s", plan)
+	}
+	if _, ok := app.lastLaunchTime["Celeste"]; ok {
+		t.Error("a dry run must not record a launch")
+	}
+	if entries, _ := readHistory(app.historyPath); len(entries) != 0 {
+		t.Errorf("a dry run must not write history, got %+v", entries)
+	}
+}
+
+func TestLoadConfig_ReadOnlyWritesNothing(t *testing.T) {
+	app, dir := newTestApp(t)
+	app.readOnly = true
+	app.loadConfig()
+	if fileExists(app.configPath) || app.currentConfig() == nil {
+		t.Fatal("a dry run should use the defaults without creating config.yaml")
+	}
+
+	old := "games:\n  - game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    schedules:\n      - days: [friday]\n        start_time: \"19:00\"\n        end_time: \"21:00\"\n"
+	os.WriteFile(app.configPath, []byte(old), 0644)
+	app.loadConfig()
+	if games := app.currentConfig().Games; len(games) != 1 || games[0].ID == "" || games[0].Schedules[0].Days[0] != "Fri" {
+		t.Errorf("the config should still be migrated in memory: %+v", games)
+	}
+	if data, _ := os.ReadFile(app.configPath); string(data) != old {
+		t.Errorf("a dry run must not rewrite config.yaml:\n%s", data)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("a dry run must not write backups, found %d files", len(entries))
+	}
+}
+
+func TestDryRun_PrintsConfigProblems(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.readOnly = true
+	os.WriteFile(app.configPath, []byte("games:\n  - game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    launch_method: teleport\n"), 0644)
+	app.loadConfig()
+	var out bytes.Buffer
+	app.dryRun(&out)
+	if !strings.Contains(out.String(), "config.yaml has 1 problem(s):\n  line 4") || !strings.Contains(out.String(), `unknown launch method "teleport"`) {
+		t.Errorf("expected the problem in the dry-run output, got:\n%s", out.String())
+	}
+
+	app, _ = newTestApp(t)
+	app.readOnly = true
+	os.WriteFile(app.configPath, []byte("games: [\n"), 0644)
+	app.loadConfig()
+	out.Reset()
+	app.dryRun(&out)
+	if !strings.HasPrefix(out.String(), "config.yaml could not be loaded; the plan below uses the defaults:\n  line ") {
+		t.Errorf("expected the rejected config in the dry-run output, got:\n%s", out.String())
+	}
+}
+
+func TestQuoteArgs_RoundTripsThroughSplitArgs(t *testing.T) {
+	args := []string{"/usr/bin/game", "--name", "it's fine", "", `C:\Games`}
+	got, err := splitArgs(quoteArgs(args))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(got, "|") != strings.Join(args, "|") {
+		t.Errorf("round trip = %q, want %q", got, args)
+	}
+}
+
+func TestAddedEnv(t *testing.T) {
+	got := addedEnv([]string{"HOME=/home/me", "PATH=/bin", "FOO=1"}, []string{"HOME=/home/me", "PATH=/bin"})
+	if len(got) != 1 || got[0] != "FOO=1" {
+		t.Errorf("addedEnv() = %q", got)
+	}
+	if addedEnv(exec.Command("true").Env, []string{"HOME=/home/me"}) != nil {
+		t.Error("an inherited environment adds nothing")
+	}
+}
+
+func TestShowTestLaunch_ShowsPlan(t *testing.T) {
+	game := dryRunGame(time.Now())
+	ui := newTestUI(t, []Game{game})
+	ui.showTestLaunch(game)
+
+	overlays := ui.window.Canvas().Overlays().List()
+	if len(overlays) == 0 {
+		t.Fatal("expected the Test Launch dialog to be shown")
+	}
+	if !containsLabelText(overlays[0], "command: /opt/celeste/Celeste") {
+		t.Error("expected the dialog to show the resolved command")
+	}
+}
+
+// containsLabelText reports whether any label under obj contains text.
+func containsLabelText(obj fyne.CanvasObject, text string) bool {
+	switch o := obj.(type) {
+	case *widget.Label:
+		return strings.Contains(o.Text, text)
+	case *fyne.Container:
+		for _, child := range o.Objects {
+			if containsLabelText(child, text) {
+				return true
+			}
+		}
+		return false
+	case fyne.Widget:
+		for _, child := range fynetest.WidgetRenderer(o).Objects() {
+			if containsLabelText(child, text) {
+				return true
+			}
+		}
+	}
+	return false
+}
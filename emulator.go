@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// romPlaceholder marks where an emulator profile's args take the ROM path.
+const romPlaceholder = "{rom}"
+
+// Emulator is a named emulator profile from the config. Games using the
+// "emulator" launch method reference one by name and set game_path to a ROM.
+type Emulator struct {
+	Binary string `yaml:"binary"`         // e.g. "retroarch" or "/usr/bin/dolphin-emu"
+	Args   string `yaml:"args,omitempty"` // shell-quoted template, e.g. "-L snes9x_libretro.so {rom}"
+}
+
+// emulatorArgv expands profile's argument template for rom. A template
+// without {rom} gets the ROM appended, which is what most emulators expect.
+func emulatorArgv(profile Emulator, rom string) ([]string, error) {
+	if strings.TrimSpace(profile.Binary) == "" {
+		return nil, fmt.Errorf("emulator profile has no binary")
+	}
+	args, err := splitArgs(profile.Args)
+	if err != nil {
+		return nil, fmt.Errorf("emulator args: %w", err)
+	}
+	argv := []string{profile.Binary}
+	found := false
+	for _, a := range args {
+		if strings.Contains(a, romPlaceholder) {
+			found = true
+			a = strings.ReplaceAll(a, romPlaceholder, rom)
+		}
+		argv = append(argv, a)
+	}
+	if !found {
+		argv = append(argv, rom)
+	}
+	return argv, nil
+}
+
+// emulatorLaunchCmd builds the command for the "emulator" method from the
+// profile resolveLaunch attached to the game.
+func emulatorLaunchCmd(game Game) *exec.Cmd {
+	var program []string
+	err := fmt.Errorf("unknown emulator profile %q", game.Emulator)
+	if game.emulatorProfile != nil {
+		program, err = emulatorArgv(*game.emulatorProfile, game.GamePath)
+	}
+	if err != nil {
+		// Let Start report the problem rather than running something else.
+		cmd := exec.Command(game.GamePath)
+		cmd.Err = fmt.Errorf("%s: %w", game.GameName, err)
+		return cmd
+	}
+	cmd := execGameCmd(game, program)
+	cmd.Env = gameEnv(game.Env)
+	cmd.Dir = game.WorkingDir
+	return cmd
+}
+
+// emulatorNames returns the config's emulator profile names, sorted.
+func emulatorNames(emulators map[string]Emulator) []string {
+	names := make([]string, 0, len(emulators))
+	for name := range emulators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
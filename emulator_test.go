@@ -0,0 +1,98 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Emulator profiles
+// ============================================================================
+
+func TestEmulatorArgv(t *testing.T) {
+	cases := []struct {
+		name    string
+		profile Emulator
+		want    []string
+	}{
+		{
+			"placeholder",
+			Emulator{Binary: "retroarch", Args: "-L /cores/snes9x_libretro.so {rom}"},
+			[]string{"retroarch", "-L", "/cores/snes9x_libretro.so", "/roms/Super Metroid.sfc"},
+		},
+		{
+			"placeholder inside an arg",
+			Emulator{Binary: "dolphin-emu", Args: "-b --exec={rom}"},
+			[]string{"dolphin-emu", "-b", "--exec=/roms/Super Metroid.sfc"},
+		},
+		{
+			"no placeholder appends rom",
+			Emulator{Binary: "pcsx2-qt", Args: "-fullscreen"},
+			[]string{"pcsx2-qt", "-fullscreen", "/roms/Super Metroid.sfc"},
+		},
+		{
+			"no args",
+			Emulator{Binary: "mgba"},
+			[]string{"mgba", "/roms/Super Metroid.sfc"},
+		},
+	}
+	for _, c := range cases {
+		got, err := emulatorArgv(c.profile, "/roms/Super Metroid.sfc")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: emulatorArgv() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestEmulatorArgv_Errors(t *testing.T) {
+	if _, err := emulatorArgv(Emulator{Args: "{rom}"}, "/roms/a.sfc"); err == nil {
+		t.Error("expected an error for a profile without a binary")
+	}
+	if _, err := emulatorArgv(Emulator{Binary: "retroarch", Args: `-L "unterminated`}, "/roms/a.sfc"); err == nil {
+		t.Error("expected an error for unparseable args")
+	}
+}
+
+func TestBuildLaunchCmd_EmulatorResolvesProfile(t *testing.T) {
+	app := appWithGames(nil)
+	app.config.Emulators = map[string]Emulator{
+		"snes": {Binary: "retroarch", Args: "-L snes9x_libretro.so {rom}"},
+	}
+	game := app.resolveLaunch(Game{
+		GameName:     "Chrono Trigger",
+		GamePath:     "/roms/chrono.sfc",
+		LaunchMethod: "emulator",
+		Emulator:     "snes",
+		LaunchArgs:   "--verbose",
+		Wrappers:     []string{"gamemoderun"},
+	})
+	cmd := buildLaunchCmd(game, "linux")
+	want := "gamemoderun retroarch -L snes9x_libretro.so /roms/chrono.sfc --verbose"
+	if got := strings.Join(cmd.Args, " "); got != want {
+		t.Errorf("buildLaunchCmd() args = %q, want %q", got, want)
+	}
+}
+
+func TestBuildLaunchCmd_EmulatorUnknownProfile(t *testing.T) {
+	app := appWithGames(nil)
+	game := app.resolveLaunch(Game{GameName: "Zelda", GamePath: "/roms/zelda.sfc", LaunchMethod: "emulator", Emulator: "missing"})
+	cmd := buildLaunchCmd(game, "linux")
+	if cmd.Err == nil || !strings.Contains(cmd.Err.Error(), `"missing"`) {
+		t.Errorf("expected an unknown profile error, got %v", cmd.Err)
+	}
+	if err := cmd.Start(); err == nil {
+		t.Error("expected Start to fail for an unknown emulator profile")
+	}
+}
+
+func TestEmulatorNames_Sorted(t *testing.T) {
+	got := emulatorNames(map[string]Emulator{"ps2": {}, "gc": {}, "snes": {}})
+	if want := []string{"gc", "ps2", "snes"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("emulatorNames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// newGameID returns a random ID that no game in games uses.
+func newGameID(games []Game) string {
+	for {
+		b := make([]byte, 4)
+		rand.Read(b)
+		id := hex.EncodeToString(b)
+		if gameIndex(games, id) < 0 {
+			return id
+		}
+	}
+}
+
+// assignGameIDs gives every game without an ID, or with one an earlier game
+// already uses (e.g. a copy-pasted entry), a new ID. It returns what changed.
+func assignGameIDs(cfg *Config) []string {
+	var changes []string
+	seen := make(map[string]bool, len(cfg.Games))
+	for i := range cfg.Games {
+		game := &cfg.Games[i]
+		if game.ID != "" && !seen[game.ID] {
+			seen[game.ID] = true
+			continue
+		}
+		old := game.ID
+		game.ID = newGameID(cfg.Games)
+		seen[game.ID] = true
+		if old == "" {
+			changes = append(changes, fmt.Sprintf("%s: assigned id %s", game.GameName, game.ID))
+		} else {
+			changes = append(changes, fmt.Sprintf("%s: id %s is already used, assigned %s", game.GameName, old, game.ID))
+		}
+	}
+	return changes
+}
+
+// key identifies game in launch times, issues and the manager window: its
+// ID, or its name while it has none yet.
+func (game Game) key() string {
+	if game.ID != "" {
+		return game.ID
+	}
+	return game.GameName
+}
+
+// gameIndex returns the position of the game with the given key in games,
+// or -1.
+func gameIndex(games []Game, key string) int {
+	for i, game := range games {
+		if game.key() == key {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Game IDs
+// ============================================================================
+
+func TestAssignGameIDs(t *testing.T) {
+	cfg := &Config{Games: []Game{
+		{GameName: "Celeste", ID: "c0ffee00"},
+		{GameName: "Celeste copy", ID: "c0ffee00"},
+		{GameName: "Portal"},
+	}}
+	changes := assignGameIDs(cfg)
+
+	if cfg.Games[0].ID != "c0ffee00" {
+		t.Errorf("an existing ID must be kept, got %q", cfg.Games[0].ID)
+	}
+	if id := cfg.Games[1].ID; id == "" || id == "c0ffee00" {
+		t.Errorf("a duplicate ID should be replaced, got %q", id)
+	}
+	if id := cfg.Games[2].ID; len(id) != 8 || id == cfg.Games[1].ID {
+		t.Errorf("expected a new unique ID, got %q", id)
+	}
+	if len(changes) != 2 || !strings.Contains(changes[0], "already used") {
+		t.Errorf("unexpected changes %q", changes)
+	}
+	if assignGameIDs(cfg) != nil {
+		t.Error("IDs should be stable once assigned")
+	}
+}
+
+func TestLoadConfig_PersistsAssignedIDs(t *testing.T) {
+	app, _ := newTestApp(t)
+	os.WriteFile(app.configPath, []byte(commentedConfig), 0644)
+	app.loadConfig()
+	first := app.currentConfig().Games
+	if first[0].ID == "" || first[1].ID == "" {
+		t.Fatalf("expected IDs to be assigned on load, got %+v", first)
+	}
+
+	app.loadConfig()
+	second := app.currentConfig().Games
+	if second[0].ID != first[0].ID || second[1].ID != first[1].ID {
+		t.Errorf("IDs changed between loads: %q,%q then %q,%q", first[0].ID, first[1].ID, second[0].ID, second[1].ID)
+	}
+	if data, _ := os.ReadFile(app.configPath); !strings.Contains(string(data), "# Weekend game") {
+		t.Errorf("assigning IDs should keep the file's comments:\n%s", data)
+	}
+}
+
+func TestLastLaunch_SurvivesRenameAndSeparatesNamesakes(t *testing.T) {
+	now := time.Now()
+	game := gameWithSchedule(now.Weekday().String()[:3], "00:00", "23:59")
+	game.ID = "aaaa0001"
+	namesake := game
+	namesake.ID = "aaaa0002"
+	app := appWithGames([]Game{game, namesake})
+
+	app.recordLaunch(game)
+	renamed := game
+	renamed.GameName = "Renamed"
+	if !app.hasLaunchedInCurrentWindow(renamed) {
+		t.Error("renaming a game should keep its launch record")
+	}
+	if app.hasLaunchedInCurrentWindow(namesake) {
+		t.Error("a game with the same name is a different game")
+	}
+}
+
+func TestHistory_RecordsGameID(t *testing.T) {
+	app := appWithGames(nil)
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	app.recordHistory(Game{ID: "aaaa0001", GameName: "Celeste"}, historyLaunched, "")
+
+	entries, err := readHistory(app.historyPath)
+	if err != nil || len(entries) != 1 || entries[0].GameID != "aaaa0001" || entries[0].Game != "Celeste" {
+		t.Errorf("readHistory() = %+v, %v", entries, err)
+	}
+}
+
+func TestUpdateGame_ByIDAmongNamesakes(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.config.Games = []Game{
+		{ID: "aaaa0001", GameName: "Celeste", Enabled: true},
+		{ID: "aaaa0002", GameName: "Celeste", Enabled: true},
+	}
+	app.updateGame("aaaa0002", func(g *Game) { g.Enabled = false })
+	games := app.currentConfig().Games
+	if !games[0].Enabled || games[1].Enabled {
+		t.Errorf("only the second game should be disabled: %+v", games)
+	}
+
+	app.removeGame("aaaa0001")
+	if games := app.currentConfig().Games; len(games) != 1 || games[0].ID != "aaaa0002" {
+		t.Errorf("expected only the second game left, got %+v", games)
+	}
+}
+
+func TestUpdateConfig_AssignsIDsToNewGames(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.updateConfig(func(cfg *Config) { cfg.Games = append(cfg.Games, Game{GameName: "Celeste"}) })
+	if app.currentConfig().Games[0].ID == "" {
+		t.Error("a game added in the UI should get an ID")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxGameLogSize is the size at which a game's output log is rotated to .1.
+const maxGameLogSize = 2 * 1024 * 1024
+
+// gameLogPath returns the file a direct launch's stdout and stderr are
+// captured to, or "" when output capture is disabled. It is keyed by the
+// game's ID so renaming the game keeps its log.
+func (app *App) gameLogPath(game Game) string {
+	if app.gameLogDir == "" {
+		return ""
+	}
+	return filepath.Join(app.gameLogDir, gameLogName(game.key()))
+}
+
+// migrateGameLog moves a log that earlier versions kept under the game's
+// name to its ID-keyed path, unless that already exists.
+func (app *App) migrateGameLog(game Game) {
+	path := app.gameLogPath(game)
+	if path == "" || game.ID == "" || fileExists(path) {
+		return
+	}
+	old := filepath.Join(app.gameLogDir, gameLogName(game.GameName))
+	for _, suffix := range []string{"", ".1"} {
+		if !fileExists(old + suffix) {
+			continue
+		}
+		if err := os.Rename(old+suffix, path+suffix); err != nil {
+			log.Printf("Warning: could not move game log %s: %v", old+suffix, err)
+		}
+	}
+}
+
+// gameLogName turns a game ID or name into a safe file name, e.g.
+// "Baldur's Gate 3" -> "baldur-s-gate-3.log".
+func gameLogName(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return '-'
+	}, name)
+	for strings.Contains(slug, "--") {
+		slug = strings.ReplaceAll(slug, "--", "-")
+	}
+	slug = strings.Trim(slug, "-")
+	if slug == "" {
+		slug = "game"
+	}
+	return slug + ".log"
+}
+
+// captureOutput points a direct launch's stdout and stderr at the game's log
+// file, rotating it first if it has grown too large. The returned func closes
+// our copy of the file and must be called once the command has been started
+// (or failed to start); the child keeps its own descriptor.
+func (app *App) captureOutput(game Game, cmd *exec.Cmd) func() {
+	path := app.gameLogPath(game)
+	if path == "" || isProtocolLaunch(game) {
+		return func() {}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.Printf("Warning: could not create game log directory: %v", err)
+		return func() {}
+	}
+	app.migrateGameLog(game)
+	if info, err := os.Stat(path); err == nil && info.Size() > maxGameLogSize {
+		rotated := path + ".1"
+		os.Remove(rotated)
+		os.Rename(path, rotated)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Warning: could not open game log %s: %v", path, err)
+		return func() {}
+	}
+	fmt.Fprintf(f, "=== %s: %s ===\n", time.Now().Format(time.DateTime), strings.Join(cmd.Args, " "))
+	cmd.Stdout = f
+	cmd.Stderr = f
+	return func() { f.Close() }
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGameLogName(t *testing.T) {
+	cases := map[string]string{
+		"Baldur's Gate 3": "baldur-s-gate-3.log",
+		"Stardew Valley":  "stardew-valley.log",
+		"  ../etc  ":      "etc.log",
+		"":                "game.log",
+	}
+	for in, want := range cases {
+		if got := gameLogName(in); got != want {
+			t.Errorf("gameLogName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGameLogPath_DisabledWithoutDir(t *testing.T) {
+	if got := appWithGames(nil).gameLogPath(Game{GameName: "A"}); got != "" {
+		t.Errorf("expected no log path without gameLogDir, got %q", got)
+	}
+}
+
+func TestGameLogPath_KeyedByID(t *testing.T) {
+	app := appWithGames(nil)
+	app.gameLogDir = t.TempDir()
+	before := app.gameLogPath(Game{ID: "aaaa0001", GameName: "Celeste"})
+	after := app.gameLogPath(Game{ID: "aaaa0001", GameName: "Celeste (2018)"})
+	if before != after || filepath.Base(before) != "aaaa0001.log" {
+		t.Errorf("expected the log to follow the ID across a rename, got %q and %q", before, after)
+	}
+}
+
+func TestCaptureOutput_MigratesNameKeyedLog(t *testing.T) {
+	app := appWithGames(nil)
+	app.gameLogDir = t.TempDir()
+	game := Game{ID: "aaaa0001", GameName: "Celeste", GamePath: "/usr/bin/celeste", LaunchMethod: "direct"}
+	old := filepath.Join(app.gameLogDir, "celeste.log")
+	os.WriteFile(old, []byte("earlier run\n"), 0644)
+	os.WriteFile(old+".1", []byte("older run\n"), 0644)
+
+	app.captureOutput(game, exec.Command(game.GamePath))()
+
+	data, _ := os.ReadFile(app.gameLogPath(game))
+	if !strings.HasPrefix(string(data), "earlier run\n") {
+		t.Errorf("expected the name-keyed log to be carried over, got:\n%s", data)
+	}
+	if !fileExists(app.gameLogPath(game)+".1") || fileExists(old) || fileExists(old+".1") {
+		t.Error("expected the name-keyed log and its rotation to be moved")
+	}
+}
+
+func TestCaptureOutput_WritesChildOutput(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	app := appWithGames(nil)
+	app.gameLogDir = filepath.Join(t.TempDir(), "games")
+	game := Game{GameName: "Noisy", GamePath: "/bin/sh", LaunchMethod: "direct", LaunchArgs: `-c 'echo out; echo err >&2'`}
+
+	cmd := buildLaunchCmd(game, runtime.GOOS)
+	closeOutput := app.captureOutput(game, cmd)
+	err := cmd.Run()
+	closeOutput()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(app.gameLogPath(game))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"=== ", "/bin/sh", "out\n", "err\n"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %q in game log, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestCaptureOutput_SkipsProtocolLaunches(t *testing.T) {
+	app := appWithGames(nil)
+	app.gameLogDir = t.TempDir()
+	cmd := exec.Command("xdg-open", "steam://rungameid/1")
+	app.captureOutput(Game{GameName: "S", LaunchMethod: "steam"}, cmd)()
+	if cmd.Stdout != nil {
+		t.Error("protocol launches should not capture the opener's output")
+	}
+}
+
+func TestCaptureOutput_Rotates(t *testing.T) {
+	app := appWithGames(nil)
+	app.gameLogDir = t.TempDir()
+	game := Game{GameName: "Big", GamePath: "/usr/bin/big", LaunchMethod: "direct"}
+	path := app.gameLogPath(game)
+	if err := os.WriteFile(path, make([]byte, maxGameLogSize+1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	app.captureOutput(game, exec.Command(game.GamePath))()
+	if !fileExists(path + ".1") {
+		t.Error("expected oversized log to be rotated to .1")
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() > 1024 {
+		t.Errorf("expected a fresh log after rotation, got %v, %v", info, err)
+	}
+}
+
+func runDirect(t *testing.T, app *App, game Game) {
+	t.Helper()
+	proc, err := startLaunch(game, buildLaunchCmd(game, runtime.GOOS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.trackSession(proc)
+}
+
+func TestTrackSession_RecordsCrash(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	app := appWithGames(nil)
+	app.config.CrashWindow = 60
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	game := Game{GameName: "Crashy", GamePath: "/bin/sh", LaunchMethod: "direct", LaunchArgs: "-c 'exit 3'"}
+
+	runDirect(t, app, game)
+
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyCrashed {
+		t.Fatalf("expected one crashed entry, got %+v", entries)
+	}
+	if entries[0].ExitCode == nil || *entries[0].ExitCode != 3 {
+		t.Errorf("expected exit code 3, got %v", entries[0].ExitCode)
+	}
+	if !strings.Contains(app.launchFailure, "Crashy crashed (exit 3)") {
+		t.Errorf("expected crash in tray status, got %q", app.launchFailure)
+	}
+	if got := gameStatusLabelAt(app, game, time.Now()); !strings.Contains(got, "Crashed") {
+		t.Errorf("expected crash in manager status, got %q", got)
+	}
+}
+
+func TestTrackSession_RecordsSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	app := appWithGames(nil)
+	app.config.CrashWindow = 60
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	game := Game{GameName: "Killed", GamePath: "/bin/sh", LaunchMethod: "direct", LaunchArgs: "-c 'kill -KILL $$'"}
+
+	runDirect(t, app, game)
+
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Signal != "killed" {
+		t.Errorf("expected a killed signal entry, got %+v", entries)
+	}
+}
+
+func TestTrackSession_CleanExitIsNotACrash(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	app := appWithGames(nil)
+	app.config.CrashWindow = 60
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	game := Game{GameName: "Fine", GamePath: "/bin/sh", LaunchMethod: "direct", LaunchArgs: "-c 'exit 0'"}
+
+	runDirect(t, app, game)
+
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyExited || *entries[0].ExitCode != 0 {
+		t.Errorf("expected one clean exited entry, got %+v", entries)
+	}
+	if app.launchFailure != "" {
+		t.Errorf("clean exit should not set a tray failure, got %q", app.launchFailure)
+	}
+}
+
+func TestTrackSession_LateFailureIsNotACrash(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	app := appWithGames(nil)
+	app.config.CrashWindow = 0
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	game := Game{GameName: "Late", GamePath: "/bin/sh", LaunchMethod: "direct", LaunchArgs: "-c 'exit 2'"}
+
+	runDirect(t, app, game)
+
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyExited {
+		t.Errorf("expected exited (not crashed) outside the crash window, got %+v", entries)
+	}
+}
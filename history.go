@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+)
+
+// History events recorded for each game.
+const (
+	historyLaunched     = "launched"
+	historyLaunchFailed = "launch_failed"
+	historyExited       = "exited"
+	historyCrashed      = "crashed"
+)
+
+// HistoryEntry is one line of the launch history file.
+type HistoryEntry struct {
+	Time   time.Time `json:"time"`
+	Game   string    `json:"game"`
+	GameID string    `json:"game_id,omitempty"`
+	Event  string    `json:"event"`
+	Detail string    `json:"detail,omitempty"`
+
+	// Set for exited/crashed entries of direct launches.
+	ExitCode *int   `json:"exit_code,omitempty"`
+	Signal   string `json:"signal,omitempty"`
+}
+
+// historyFileName lives next to the log file in appLogDir().
+const historyFileName = "history.jsonl"
+
+// recordHistory appends an entry to the history file. It is a no-op when no
+// history path is configured (e.g. in tests).
+func (app *App) recordHistory(game Game, event, detail string) {
+	app.appendHistory(HistoryEntry{Time: time.Now(), Game: game.GameName, GameID: game.ID, Event: event, Detail: detail})
+}
+
+func (app *App) appendHistory(entry HistoryEntry) {
+	if app.historyPath == "" {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Error encoding history entry: %v", err)
+		return
+	}
+	f, err := os.OpenFile(app.historyPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Error opening history file: %v", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		log.Printf("Error writing history: %v", err)
+	}
+}
+
+// readHistory returns every entry in the history file, oldest first.
+// Malformed lines are skipped.
+func readHistory(path string) ([]HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []HistoryEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordHistory_AppendsAndReads(t *testing.T) {
+	app := appWithGames(nil)
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+
+	app.recordHistory(Game{GameName: "A"}, historyLaunched, "")
+	app.recordHistory(Game{GameName: "B"}, historyLaunchFailed, "boom")
+
+	entries, err := readHistory(app.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	if entries[1].Game != "B" || entries[1].Event != historyLaunchFailed || entries[1].Detail != "boom" {
+		t.Errorf("unexpected second entry: %+v", entries[1])
+	}
+	if entries[0].Time.IsZero() {
+		t.Error("expected entries to be timestamped")
+	}
+}
+
+func TestRecordHistory_NoPathIsNoop(t *testing.T) {
+	appWithGames(nil).recordHistory(Game{GameName: "A"}, historyLaunched, "") // must not panic
+}
+
+func TestReadHistory_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFileName)
+	data := "not json\n{\"game\":\"A\",\"event\":\"launched\"}\n"
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := readHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Game != "A" {
+		t.Errorf("expected only the valid entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Hook is a shell command run around a game session, e.g. switching audio
+// output before launch or backing up saves afterwards.
+type Hook struct {
+	Command        string `yaml:"command"`
+	Timeout        int    `yaml:"timeout,omitempty"`          // seconds; 0 means defaultHookTimeout
+	AbortOnFailure bool   `yaml:"abort_on_failure,omitempty"` // pre_launch only: cancel the launch if this hook fails
+}
+
+type Hooks struct {
+	PreLaunch   []Hook `yaml:"pre_launch,omitempty"`
+	PostSession []Hook `yaml:"post_session,omitempty"`
+}
+
+const (
+	hookPreLaunch   = "pre_launch"
+	hookPostSession = "post_session"
+
+	defaultHookTimeout = 30 * time.Second
+)
+
+// mergeHooks wraps the game's hooks in the global ones: global pre-launch
+// hooks run first and global post-session hooks run last, so machine-wide
+// setup brackets anything game specific.
+func mergeHooks(global, own Hooks) Hooks {
+	return Hooks{
+		PreLaunch:   append(append([]Hook{}, global.PreLaunch...), own.PreLaunch...),
+		PostSession: append(append([]Hook{}, own.PostSession...), global.PostSession...),
+	}
+}
+
+// hookCommand returns the shell invocation for a hook command line.
+func hookCommand(ctx context.Context, command, goos string) *exec.Cmd {
+	if goos == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// hookEnv describes the game to a hook through FRICTIONLESS_* variables.
+func hookEnv(game Game, event string) []string {
+	return append(os.Environ(),
+		"FRICTIONLESS_EVENT="+event,
+		"FRICTIONLESS_GAME_ID="+game.ID,
+		"FRICTIONLESS_GAME_NAME="+game.GameName,
+		"FRICTIONLESS_GAME_PATH="+game.GamePath,
+		"FRICTIONLESS_LAUNCH_METHOD="+game.LaunchMethod,
+		"FRICTIONLESS_LAUNCH_ARGS="+game.LaunchArgs,
+	)
+}
+
+// runHook runs a single hook to completion or until its timeout expires.
+func runHook(game Game, event string, hook Hook) error {
+	timeout := defaultHookTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := hookCommand(ctx, hook.Command, runtime.GOOS)
+	cmd.Env = hookEnv(game, event)
+	// Don't let a backgrounded grandchild holding the output pipe keep us
+	// waiting past the timeout.
+	cmd.WaitDelay = time.Second
+
+	out, err := cmd.CombinedOutput()
+	if output := strings.TrimSpace(string(out)); output != "" {
+		log.Printf("%s hook output for %s: %s", event, game.GameName, output)
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", timeout)
+	}
+	return err
+}
+
+// runHooks runs hooks in order. Failures are logged and skipped, except for a
+// failing hook marked abort_on_failure, whose error is returned immediately
+// so the caller can cancel the launch.
+func runHooks(game Game, event string, hooks []Hook) error {
+	for _, hook := range hooks {
+		if strings.TrimSpace(hook.Command) == "" {
+			continue
+		}
+		log.Printf("Running %s hook for %s: %s", event, game.GameName, hook.Command)
+		if err := runHook(game, event, hook); err != nil {
+			if hook.AbortOnFailure && event == hookPreLaunch {
+				return fmt.Errorf("%s hook %q failed: %w", event, hook.Command, err)
+			}
+			log.Printf("Warning: %s hook %q failed for %s: %v", event, hook.Command, game.GameName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func skipOnWindows(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use POSIX sh")
+	}
+}
+
+func TestMergeHooks_GlobalBracketsGame(t *testing.T) {
+	global := Hooks{
+		PreLaunch:   []Hook{{Command: "global-pre"}},
+		PostSession: []Hook{{Command: "global-post"}},
+	}
+	own := Hooks{
+		PreLaunch:   []Hook{{Command: "game-pre"}},
+		PostSession: []Hook{{Command: "game-post"}},
+	}
+	got := mergeHooks(global, own)
+	if got.PreLaunch[0].Command != "global-pre" || got.PreLaunch[1].Command != "game-pre" {
+		t.Errorf("pre-launch order = %v, want global then game", got.PreLaunch)
+	}
+	if got.PostSession[0].Command != "game-post" || got.PostSession[1].Command != "global-post" {
+		t.Errorf("post-session order = %v, want game then global", got.PostSession)
+	}
+}
+
+func TestHookCommand_Shell(t *testing.T) {
+	if cmd := hookCommand(t.Context(), "echo hi", "windows"); cmd.Args[0] != "cmd" || cmd.Args[1] != "/C" {
+		t.Errorf("expected cmd /C on windows, got %v", cmd.Args)
+	}
+	if cmd := hookCommand(t.Context(), "echo hi", "linux"); cmd.Args[0] != "sh" || cmd.Args[1] != "-c" {
+		t.Errorf("expected sh -c on linux, got %v", cmd.Args)
+	}
+}
+
+func TestRunHooks_ExposesGameMetadata(t *testing.T) {
+	skipOnWindows(t)
+	out := filepath.Join(t.TempDir(), "env.txt")
+	game := Game{GameName: "Stardew Valley", GamePath: "steam://rungameid/413150", LaunchMethod: "steam"}
+	hooks := []Hook{{Command: `echo "$FRICTIONLESS_EVENT|$FRICTIONLESS_GAME_NAME|$FRICTIONLESS_LAUNCH_METHOD" > ` + out}}
+
+	if err := runHooks(game, hookPreLaunch, hooks); err != nil {
+		t.Fatalf("runHooks: %v", err)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "pre_launch|Stardew Valley|steam" {
+		t.Errorf("hook saw %q", got)
+	}
+}
+
+func TestRunHooks_FailureWithoutAbortContinues(t *testing.T) {
+	skipOnWindows(t)
+	out := filepath.Join(t.TempDir(), "ran")
+	hooks := []Hook{{Command: "exit 3"}, {Command: "touch " + out}}
+	if err := runHooks(Game{GameName: "G"}, hookPreLaunch, hooks); err != nil {
+		t.Errorf("expected failure to be logged, not returned: %v", err)
+	}
+	if !fileExists(out) {
+		t.Error("expected the hook after the failing one to still run")
+	}
+}
+
+func TestRunHooks_AbortOnFailure(t *testing.T) {
+	skipOnWindows(t)
+	out := filepath.Join(t.TempDir(), "ran")
+	hooks := []Hook{{Command: "exit 3", AbortOnFailure: true}, {Command: "touch " + out}}
+	if err := runHooks(Game{GameName: "G"}, hookPreLaunch, hooks); err == nil {
+		t.Error("expected abort_on_failure hook to return an error")
+	}
+	if fileExists(out) {
+		t.Error("hooks after an aborting failure should not run")
+	}
+}
+
+func TestRunHooks_AbortIgnoredAfterSession(t *testing.T) {
+	skipOnWindows(t)
+	hooks := []Hook{{Command: "exit 3", AbortOnFailure: true}}
+	if err := runHooks(Game{GameName: "G"}, hookPostSession, hooks); err != nil {
+		t.Errorf("post-session hooks cannot abort anything, got %v", err)
+	}
+}
+
+func TestRunHook_Timeout(t *testing.T) {
+	skipOnWindows(t)
+	start := time.Now()
+	err := runHook(Game{GameName: "G"}, hookPreLaunch, Hook{Command: "sleep 10", Timeout: 1})
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("hook ran for %s, expected it to be killed after ~1s", elapsed)
+	}
+}
+
+func TestLaunchGameByStruct_PreHookAborts(t *testing.T) {
+	skipOnWindows(t)
+	dir := t.TempDir()
+	post := filepath.Join(dir, "post")
+	game := Game{
+		GameName:     "Hooked",
+		GamePath:     "/absolutely/does/not/exist/game",
+		LaunchMethod: "direct",
+		Hooks: Hooks{
+			PreLaunch:   []Hook{{Command: "exit 1", AbortOnFailure: true}},
+			PostSession: []Hook{{Command: "touch " + post}},
+		},
+	}
+	app := appWithGames([]Game{game})
+	app.historyPath = filepath.Join(dir, historyFileName)
+	app.launchGameByStruct(game)
+
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyLaunchFailed || !strings.Contains(entries[0].Detail, "pre_launch hook") {
+		t.Errorf("expected the aborted launch recorded as failed, got %+v", entries)
+	}
+	if _, ok := app.lastLaunchTime[game.GameName]; !ok {
+		t.Error("an aborted launch should use up its window like any failed one")
+	}
+	if app.launchFailure == "" {
+		t.Error("expected the aborted launch to be surfaced")
+	}
+	if fileExists(post) {
+		t.Error("post-session hooks should not run when the pre-launch ones were cut short")
+	}
+}
+
+func TestTrackSession_DirectRunsPostHooks(t *testing.T) {
+	skipOnWindows(t)
+	out := filepath.Join(t.TempDir(), "post")
+	game := Game{
+		GameName:     "Quick",
+		GamePath:     "/bin/true",
+		LaunchMethod: "direct",
+		Hooks:        Hooks{PostSession: []Hook{{Command: "touch " + out}}},
+	}
+	proc, err := startLaunch(game, buildLaunchCmd(game, runtime.GOOS))
+	if err != nil {
+		t.Skipf("cannot start /bin/true: %v", err)
+	}
+	appWithGames(nil).trackSession(proc)
+	if !fileExists(out) {
+		t.Error("expected post-session hook to run after the game exited")
+	}
+}
+
+func TestWaitForSessionEnd(t *testing.T) {
+	oldPoll, oldGrace := sessionPollInterval, sessionStartGrace
+	sessionPollInterval, sessionStartGrace = time.Millisecond, 20*time.Millisecond
+	t.Cleanup(func() { sessionPollInterval, sessionStartGrace = oldPoll, oldGrace })
+
+	calls := 0
+	states := []bool{false, true, true, false}
+	ended := waitForSessionEnd(func() bool {
+		s := states[min(calls, len(states)-1)]
+		calls++
+		return s
+	})
+	if !ended {
+		t.Error("expected session to be seen starting and ending")
+	}
+
+	if waitForSessionEnd(func() bool { return false }) {
+		t.Error("expected false when the process never appears")
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// importStatus is how an imported game compares to the current games.
+type importStatus int
+
+const (
+	importAdded     importStatus = iota // no current game matches it
+	importChanged                       // matches a current game that differs
+	importUnchanged                     // matches an identical current game
+)
+
+func (s importStatus) String() string {
+	return [...]string{"added", "changed", "unchanged"}[s]
+}
+
+// importAction is what a merge import does with one imported game.
+type importAction int
+
+const (
+	importAdd       importAction = iota // add a game no current game matches
+	importSkip                          // leave out a game no current game matches
+	importKeep                          // keep the matching current game as it is
+	importOverwrite                     // replace the matching current game, keeping its ID
+	importDuplicate                     // add alongside the matching current game
+)
+
+func (a importAction) String() string {
+	return [...]string{"Add", "Skip", "Keep mine", "Overwrite", "Add as copy"}[a]
+}
+
+// importItem is one game from an import file, with the current game it
+// matched and what to do with it.
+type importItem struct {
+	Game     Game
+	Existing *Game // nil when added
+	Status   importStatus
+	Changes  []string // the keys that differ from Existing
+	Action   importAction
+}
+
+// actions lists the choices offered for item, the default first.
+func (item importItem) actions() []importAction {
+	switch item.Status {
+	case importAdded:
+		return []importAction{importAdd, importSkip}
+	case importChanged:
+		return []importAction{importOverwrite, importKeep, importDuplicate}
+	default:
+		return []importAction{importKeep, importDuplicate}
+	}
+}
+
+// parseImportedGames reads the games from an exported games file (or a whole
+// config.yaml), upgrading and validating them like config.yaml itself.
+// emulators are the current profiles, which emulator games may refer to.
+func parseImportedGames(data []byte, emulators map[string]Emulator) ([]Game, error) {
+	cfg := &Config{Emulators: emulators}
+	_, errs, _ := parseConfig(data, cfg)
+	if len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Error()
+		}
+		return nil, fmt.Errorf("invalid import file:\n%s", strings.Join(msgs, "\n"))
+	}
+	if len(cfg.Games) == 0 {
+		return nil, errors.New("no games found in file")
+	}
+	return cfg.Games, nil
+}
+
+// planImport matches each imported game to a current one, by ID and then by
+// game path, and picks the default action for it. Each current game matches
+// at most one imported game.
+func planImport(current, imported []Game) []importItem {
+	matched := make([]bool, len(current))
+	find := func(same func(Game) bool) int {
+		for i, game := range current {
+			if !matched[i] && same(game) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	items := make([]importItem, 0, len(imported))
+	for _, game := range imported {
+		i := -1
+		if game.ID != "" {
+			i = find(func(g Game) bool { return g.ID == game.ID })
+		}
+		if i < 0 && game.GamePath != "" {
+			i = find(func(g Game) bool { return g.GamePath == game.GamePath })
+		}
+
+		item := importItem{Game: game, Status: importAdded}
+		if i >= 0 {
+			matched[i] = true
+			item.Existing = &current[i]
+			item.Changes = gameChanges(current[i], game)
+			item.Status = importUnchanged
+			if len(item.Changes) > 0 {
+				item.Status = importChanged
+			}
+		}
+		item.Action = item.actions()[0]
+		items = append(items, item)
+	}
+	return items
+}
+
+// gameChanges returns the YAML keys whose values differ between a and b,
+// ignoring their IDs.
+func gameChanges(a, b Game) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	var changes []string
+	for i := 0; i < va.NumField(); i++ {
+		field := va.Type().Field(i)
+		key, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || key == "id" {
+			continue
+		}
+		// Compare as YAML so that nil and empty lists and maps are equal.
+		x, _ := yaml.Marshal(va.Field(i).Interface())
+		y, _ := yaml.Marshal(vb.Field(i).Interface())
+		if !bytes.Equal(x, y) {
+			changes = append(changes, key)
+		}
+	}
+	return changes
+}
+
+// applyImport returns games merged with items according to their actions,
+// and the keys of the games the import added or replaced.
+func applyImport(games []Game, items []importItem) (merged []Game, touched []string) {
+	merged = append([]Game(nil), games...)
+	var added []int
+	for _, item := range items {
+		game := item.Game
+		switch item.Action {
+		case importOverwrite:
+			i := gameIndex(merged, item.Existing.key())
+			if i < 0 {
+				continue // removed since the preview
+			}
+			game.ID = merged[i].ID
+			merged[i] = game
+			touched = append(touched, game.key())
+		case importDuplicate:
+			game.ID = ""
+			fallthrough
+		case importAdd:
+			added = append(added, len(merged))
+			merged = append(merged, game)
+		}
+	}
+	assignGameIDs(&Config{Games: merged}) // copies, and imported IDs already in use
+	for _, i := range added {
+		touched = append(touched, merged[i].key())
+	}
+	return merged, touched
+}
+
+// importOverlaps describes each schedule overlap between a game the import
+// added or replaced and any other game in merged.
+func importOverlaps(merged []Game, touched []string) []string {
+	var overlaps []string
+	reported := make(map[[2]string]bool)
+	for _, key := range touched {
+		i := gameIndex(merged, key)
+		if i < 0 {
+			continue
+		}
+		game := merged[i]
+		for j, other := range merged {
+			if j == i || reported[[2]string{other.key(), key}] {
+				continue
+			}
+			for _, s := range game.Schedules {
+				if day := overlappingDay(other, s.Days, s.StartTime, s.EndTime); day != "" {
+					overlaps = append(overlaps, fmt.Sprintf("%s overlaps %s on %s (%s–%s)", game.GameName, other.GameName, day, s.StartTime, s.EndTime))
+					reported[[2]string{key, other.key()}] = true
+					break
+				}
+			}
+		}
+	}
+	return overlaps
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Merge import
+// ============================================================================
+
+func importTestGames() []Game {
+	return []Game{
+		{ID: "aaaa0001", GameName: "Celeste", GamePath: "/opt/celeste", LaunchMethod: "direct", Enabled: true,
+			Schedules: []Schedule{{Days: []string{"Mon"}, StartTime: "19:00", EndTime: "21:00"}}},
+		{ID: "aaaa0002", GameName: "Portal", GamePath: "steam://rungameid/400", LaunchMethod: "steam", Enabled: true,
+			Schedules: []Schedule{{Days: []string{"Tue"}, StartTime: "19:00", EndTime: "21:00"}}},
+	}
+}
+
+func TestPlanImport_MatchesByIDThenPath(t *testing.T) {
+	current := importTestGames()
+
+	renamed := current[0]
+	renamed.GameName = "Celeste (2018)"
+	samePath := current[1]
+	samePath.ID = "bbbb0001" // exported from another machine
+	samePath.Env = map[string]string{}
+	added := Game{GameName: "Hades", GamePath: "/opt/hades", LaunchMethod: "direct"}
+
+	items := planImport(current, []Game{renamed, samePath, added})
+
+	if it := items[0]; it.Status != importChanged || it.Existing.ID != "aaaa0001" || !slices.Equal(it.Changes, []string{"game_name"}) || it.Action != importOverwrite {
+		t.Errorf("renamed game: %+v", it)
+	}
+	if it := items[1]; it.Status != importUnchanged || it.Existing.ID != "aaaa0002" || it.Action != importKeep {
+		t.Errorf("game matched by path with an empty env should be unchanged: %+v", it)
+	}
+	if it := items[2]; it.Status != importAdded || it.Existing != nil || it.Action != importAdd {
+		t.Errorf("new game: %+v", it)
+	}
+}
+
+func TestPlanImport_MatchesEachGameOnce(t *testing.T) {
+	current := importTestGames()[:1]
+	copies := []Game{current[0], current[0]}
+	copies[1].ID = ""
+
+	items := planImport(current, copies)
+	if items[0].Status != importUnchanged || items[1].Status != importAdded {
+		t.Errorf("the second copy should be added, got %s and %s", items[0].Status, items[1].Status)
+	}
+}
+
+func TestApplyImport_Actions(t *testing.T) {
+	current := importTestGames()
+	changed := current[0]
+	changed.LaunchArgs = "--fullscreen"
+	copied := current[1]
+	copied.GameName = "Portal (copy)"
+	skipped := Game{ID: "cccc0001", GameName: "Hades", GamePath: "/opt/hades"}
+
+	items := planImport(current, []Game{changed, copied, skipped})
+	items[1].Action = importDuplicate
+	items[2].Action = importSkip
+
+	merged, touched := applyImport(current, items)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 games, got %+v", merged)
+	}
+	if merged[0].ID != "aaaa0001" || merged[0].LaunchArgs != "--fullscreen" {
+		t.Errorf("overwrite should replace the game and keep its ID: %+v", merged[0])
+	}
+	if merged[1].LaunchArgs != "" || merged[1].GameName != "Portal" {
+		t.Errorf("the duplicated game's original should be untouched: %+v", merged[1])
+	}
+	if id := merged[2].ID; id == "" || id == "aaaa0002" || merged[2].GameName != "Portal (copy)" {
+		t.Errorf("the copy should be added with a new ID: %+v", merged[2])
+	}
+	if !slices.Equal(touched, []string{"aaaa0001", merged[2].ID}) {
+		t.Errorf("touched = %q", touched)
+	}
+	if current[0].LaunchArgs != "" {
+		t.Error("applyImport must not modify the games it was given")
+	}
+}
+
+func TestImportOverlaps(t *testing.T) {
+	current := importTestGames()
+	clash := Game{GameName: "Hades", GamePath: "/opt/hades",
+		Schedules: []Schedule{{Days: []string{"mon"}, StartTime: "20:00", EndTime: "22:00"}}}
+
+	merged, touched := applyImport(current, planImport(current, []Game{clash}))
+	overlaps := importOverlaps(merged, touched)
+	if len(overlaps) != 1 || !strings.Contains(overlaps[0], "Hades overlaps Celeste on mon") {
+		t.Errorf("importOverlaps() = %q", overlaps)
+	}
+
+	// A game moved into a free slot doesn't clash with its own old schedule.
+	moved := current[0]
+	moved.Schedules = []Schedule{{Days: []string{"Mon"}, StartTime: "20:00", EndTime: "22:00"}}
+	merged, touched = applyImport(current, planImport(current, []Game{moved}))
+	if overlaps := importOverlaps(merged, touched); len(overlaps) != 0 {
+		t.Errorf("expected no overlaps, got %q", overlaps)
+	}
+}
+
+func TestParseImportedGames(t *testing.T) {
+	games, err := parseImportedGames([]byte(`games:
+  - game_name: Celeste
+    game_path: /opt/celeste
+    launch_method: direct
+    schedules:
+      - days: [monday]
+        start_time: "19:00"
+        end_time: "21:00"
+`), nil)
+	if err != nil || len(games) != 1 || games[0].Schedules[0].Days[0] != "Mon" {
+		t.Errorf("parseImportedGames() = %+v, %v", games, err)
+	}
+
+	if _, err := parseImportedGames([]byte("games:\n  - game_name: Celeste\n"), nil); err == nil || !strings.Contains(err.Error(), "game_path") {
+		t.Errorf("expected a validation error, got %v", err)
+	}
+	if _, err := parseImportedGames([]byte("boot_delay: 5\n"), nil); err == nil || !strings.Contains(err.Error(), "no games") {
+		t.Errorf("expected 'no games found', got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// itchReceipt is the subset of an itch app install receipt
+// (<install>/.itch/receipt.json.gz) needed to list a game. The app's
+// butler.db has the same data, but reading SQLite would pull in a driver for
+// what the receipts already tell us.
+type itchReceipt struct {
+	Game struct {
+		Title string `json:"title"`
+	} `json:"game"`
+	Files []string `json:"files"`
+}
+
+// itchAppsDirs returns the itch app's default install location.
+func itchAppsDirs() []string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Application Support", "itch", "apps")}
+	case "windows":
+		return []string{filepath.Join(os.Getenv("APPDATA"), "itch", "apps")}
+	default:
+		return []string{filepath.Join(home, ".config", "itch", "apps")}
+	}
+}
+
+func discoverItchGames() []DiscoveredGame {
+	var games []DiscoveredGame
+	for _, dir := range itchAppsDirs() {
+		games = append(games, discoverItchGamesFrom(dir, runtime.GOOS)...)
+	}
+	return games
+}
+
+// discoverItchGamesFrom lists the installs under an itch apps directory that
+// have a receipt and a recognisable executable for goos.
+func discoverItchGamesFrom(appsDir, goos string) []DiscoveredGame {
+	entries, err := os.ReadDir(appsDir)
+	if err != nil {
+		return nil
+	}
+	var games []DiscoveredGame
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		installDir := filepath.Join(appsDir, e.Name())
+		receipt, err := readItchReceipt(filepath.Join(installDir, ".itch", "receipt.json.gz"))
+		if err != nil || receipt.Game.Title == "" {
+			continue
+		}
+		exe := itchExecutable(installDir, receipt.Files, goos)
+		if exe == "" {
+			continue
+		}
+		games = append(games, DiscoveredGame{
+			Name:         receipt.Game.Title,
+			LaunchMethod: "direct",
+			GamePath:     exe,
+		})
+	}
+	return games
+}
+
+func readItchReceipt(path string) (itchReceipt, error) {
+	var receipt itchReceipt
+	f, err := os.Open(path)
+	if err != nil {
+		return receipt, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return receipt, err
+	}
+	defer gz.Close()
+	err = json.NewDecoder(gz).Decode(&receipt)
+	return receipt, err
+}
+
+// itchRedistMarkers flag bundled installers and helpers that are executable
+// but are not the game.
+var itchRedistMarkers = []string{"unins", "redist", "vcredist", "vc_redist", "dxsetup", "directx", "crashhandler", "crashreport", "setup"}
+
+// itchExecutable picks the game's executable from the receipt's file list:
+// the shallowest candidate for goos that isn't a redistributable, preferring
+// shorter paths on ties. Returns "" when nothing looks runnable.
+func itchExecutable(installDir string, files []string, goos string) string {
+	var candidates []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		rel := filepath.FromSlash(f)
+		if goos == "darwin" {
+			// Launch the bundle's main binary rather than the .app folder.
+			idx := strings.Index(rel, ".app"+string(filepath.Separator)+"Contents"+string(filepath.Separator)+"MacOS"+string(filepath.Separator))
+			if idx < 0 || strings.Count(rel[idx:], string(filepath.Separator)) != 3 {
+				continue
+			}
+		}
+		if seen[rel] || isItchRedist(rel) {
+			continue
+		}
+		seen[rel] = true
+		path := filepath.Join(installDir, rel)
+		switch goos {
+		case "windows":
+			if !strings.EqualFold(filepath.Ext(rel), ".exe") {
+				continue
+			}
+		default:
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 || isLibraryFile(rel) {
+				continue
+			}
+		}
+		candidates = append(candidates, rel)
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		di := strings.Count(candidates[i], string(filepath.Separator))
+		dj := strings.Count(candidates[j], string(filepath.Separator))
+		if di != dj {
+			return di < dj
+		}
+		return len(candidates[i]) < len(candidates[j])
+	})
+	return filepath.Join(installDir, candidates[0])
+}
+
+func isItchRedist(rel string) bool {
+	lower := strings.ToLower(rel)
+	for _, m := range itchRedistMarkers {
+		if strings.Contains(lower, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// sharedObjectRe matches libfoo.so and versioned names such as libfoo.so.1.2.
+var sharedObjectRe = regexp.MustCompile(`\.so(\.\d+)*$`)
+
+// isLibraryFile reports whether an executable-bit file is a shared library
+// or script helper rather than something to launch.
+func isLibraryFile(rel string) bool {
+	base := strings.ToLower(filepath.Base(rel))
+	return sharedObjectRe.MatchString(base) || strings.HasSuffix(base, ".dylib") || strings.HasSuffix(base, ".dll")
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeItchInstall creates an itch install folder with a receipt listing
+// files, creating each file with the given mode.
+func writeItchInstall(t *testing.T, appsDir, folder, title string, files map[string]os.FileMode) string {
+	t.Helper()
+	installDir := filepath.Join(appsDir, folder)
+	var list []string
+	for rel, mode := range files {
+		path := filepath.Join(installDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), mode); err != nil {
+			t.Fatal(err)
+		}
+		list = append(list, rel)
+	}
+	receipt := map[string]any{"game": map[string]any{"title": title}, "files": list}
+
+	if err := os.MkdirAll(filepath.Join(installDir, ".itch"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(filepath.Join(installDir, ".itch", "receipt.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	if err := json.NewEncoder(gz).Encode(receipt); err != nil {
+		t.Fatal(err)
+	}
+	gz.Close()
+	f.Close()
+	return installDir
+}
+
+func TestDiscoverItchGamesFrom_Linux(t *testing.T) {
+	apps := t.TempDir()
+	dir := writeItchInstall(t, apps, "celeste", "Celeste", map[string]os.FileMode{
+		"Celeste/Celeste":             0755,
+		"Celeste/Celeste.bin.x86_64":  0755,
+		"Celeste/lib64/libSDL2.so.0":  0755,
+		"Celeste/Content/readme.txt":  0644,
+		"Celeste/UnityCrashHandler64": 0755,
+	})
+
+	games := discoverItchGamesFrom(apps, "linux")
+	if len(games) != 1 {
+		t.Fatalf("expected 1 game, got %+v", games)
+	}
+	g := games[0]
+	if g.Name != "Celeste" || g.LaunchMethod != "direct" {
+		t.Errorf("unexpected game: %+v", g)
+	}
+	if want := filepath.Join(dir, "Celeste", "Celeste"); g.GamePath != want {
+		t.Errorf("GamePath = %q, want %q", g.GamePath, want)
+	}
+}
+
+func TestDiscoverItchGamesFrom_Windows(t *testing.T) {
+	apps := t.TempDir()
+	dir := writeItchInstall(t, apps, "baba-is-you", "Baba Is You", map[string]os.FileMode{
+		"Baba Is You.exe":          0644,
+		"unins000.exe":             0644,
+		"redist/vc_redist.x64.exe": 0644,
+		"Data/sounds.dat":          0644,
+	})
+
+	games := discoverItchGamesFrom(apps, "windows")
+	if len(games) != 1 || games[0].GamePath != filepath.Join(dir, "Baba Is You.exe") {
+		t.Errorf("unexpected games: %+v", games)
+	}
+}
+
+func TestDiscoverItchGamesFrom_Darwin(t *testing.T) {
+	apps := t.TempDir()
+	dir := writeItchInstall(t, apps, "a-short-hike", "A Short Hike", map[string]os.FileMode{
+		"AShortHike.app/Contents/MacOS/AShortHike":            0755,
+		"AShortHike.app/Contents/Frameworks/helper/bin/thing": 0755,
+		"AShortHike.app/Contents/Info.plist":                  0644,
+	})
+
+	games := discoverItchGamesFrom(apps, "darwin")
+	want := filepath.Join(dir, "AShortHike.app", "Contents", "MacOS", "AShortHike")
+	if len(games) != 1 || games[0].GamePath != want {
+		t.Errorf("unexpected games: %+v, want path %q", games, want)
+	}
+}
+
+func TestDiscoverItchGamesFrom_SkipsWithoutReceiptOrExecutable(t *testing.T) {
+	apps := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(apps, "no-receipt"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeItchInstall(t, apps, "soundtrack", "Soundtrack", map[string]os.FileMode{
+		"track01.mp3": 0644,
+	})
+	if games := discoverItchGamesFrom(apps, "linux"); len(games) != 0 {
+		t.Errorf("expected nothing discovered, got %+v", games)
+	}
+}
+
+func TestDiscoverItchGamesFrom_MissingDir(t *testing.T) {
+	if games := discoverItchGamesFrom(filepath.Join(t.TempDir(), "nope"), "linux"); games != nil {
+		t.Errorf("expected nil for missing apps dir, got %+v", games)
+	}
+}
+
+func TestIsLibraryFile(t *testing.T) {
+	for name, want := range map[string]bool{
+		"lib/libSDL2.so":         true,
+		"lib/libSDL2-2.0.so.0":   true,
+		"lib/libfoo.so.1.2.3":    true,
+		"Game.dll":               true,
+		"libsteam_api.dylib":     true,
+		"game.sound.x86_64":      false,
+		"resolver.soul":          false,
+		"Celeste.bin.x86_64":     false,
+		"tools/solver.sh.x86_64": false,
+	} {
+		if got := isLibraryFile(name); got != want {
+			t.Errorf("isLibraryFile(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// launchMethods lists every supported launch_method, in the order the game
+// editor offers them.
+var launchMethods = []string{"steam", "epic", "battlenet", "ea", "ubisoft", "direct", "wine", "flatpak", "desktop", "emulator"}
+
+// steamAppIDRe matches the app ID in steam://rungameid/<id> and steam://run/<id> URLs.
+var steamAppIDRe = regexp.MustCompile(`^steam://(?:rungameid|run)/(\d+)`)
+
+// steamAppID extracts the numeric app ID from a Steam protocol URL.
+func steamAppID(path string) (string, bool) {
+	m := steamAppIDRe.FindStringSubmatch(path)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// steamRunURL returns the steam://run URL that starts appID with args. This is
+// the only URL form through which Steam forwards launch options to a game;
+// steam://rungameid silently drops anything appended to it. & is escaped too,
+// since on Windows the URL goes through cmd, where it would end the command.
+func steamRunURL(appID, args string) string {
+	escaped := strings.ReplaceAll(url.PathEscape(args), "&", "%26")
+	return fmt.Sprintf("steam://run/%s//%s/", appID, escaped)
+}
+
+// execGameCmd builds the command for a launch that runs program (an
+// executable plus any fixed leading args) directly: the game's wrappers, then
+// program, then its launch args.
+func execGameCmd(game Game, program []string) *exec.Cmd {
+	args, err := splitArgs(game.LaunchArgs)
+	if err != nil {
+		log.Printf("Warning: could not parse launch args for %s (%v) — splitting on whitespace", game.GameName, err)
+		args = strings.Fields(game.LaunchArgs)
+	}
+	argv, err := wrapperArgv(game.Wrappers)
+	if err != nil {
+		log.Printf("Warning: ignoring wrappers for %s: %v", game.GameName, err)
+		argv = nil
+	}
+	argv = append(argv, program...)
+	argv = append(argv, args...)
+	return exec.Command(argv[0], argv[1:]...)
+}
+
+// launchURL returns the URL handed to the OS opener for protocol-based launch
+// methods, with the game's launch args folded in where the platform allows.
+func launchURL(game Game) string {
+	if game.LaunchArgs == "" {
+		return game.GamePath
+	}
+	switch game.LaunchMethod {
+	case "steam":
+		if appID, ok := steamAppID(game.GamePath); ok {
+			return steamRunURL(appID, game.LaunchArgs)
+		}
+	}
+	return game.GamePath
+}
+
+// launchArgsWarning explains why a game's launch args would be ignored, or
+// returns "" when they will reach the game.
+func launchArgsWarning(game Game) string {
+	if game.LaunchArgs == "" {
+		return ""
+	}
+	if _, err := splitArgs(game.LaunchArgs); err != nil {
+		return fmt.Sprintf("Launch args cannot be parsed: %v", err)
+	}
+	switch game.LaunchMethod {
+	case "steam":
+		if _, ok := steamAppID(game.GamePath); !ok {
+			return "Launch args need a steam://rungameid/<appid> path and will be ignored"
+		}
+	case "epic":
+		// The Epic launcher URI has no parameter for game arguments; they
+		// have to be set in the launcher's per-game "Additional Command
+		// Line Arguments" setting instead.
+		return "Epic launcher URLs cannot pass launch args — set them in the Epic launcher instead"
+	case "battlenet", "ea", "ubisoft":
+		name := platformClients[game.LaunchMethod].Name
+		return name + " URLs cannot pass launch args — set them in " + name + " instead"
+	}
+	return ""
+}
+
+// launchEnvWarning explains why a game's env vars or working directory would
+// have no effect, or returns "" when they apply. Protocol launches hand off to
+// the platform client, which starts the game in its own environment.
+func launchEnvWarning(game Game) string {
+	if len(game.Env) == 0 && game.WorkingDir == "" {
+		return ""
+	}
+	if isProtocolLaunch(game) {
+		return "Environment and working directory only apply to direct launches — " + game.LaunchMethod + " starts the game itself"
+	}
+	return ""
+}
+
+// splitArgs splits s into arguments with splitArgsFor using this OS's rules.
+func splitArgs(s string) ([]string, error) {
+	return splitArgsFor(s, runtime.GOOS)
+}
+
+// splitArgsFor splits s into arguments using POSIX shell quoting rules: single
+// quotes preserve everything literally, double quotes allow \\, \", \$ and \`
+// escapes, and an unquoted backslash escapes the next character. No variable
+// expansion or globbing is performed. On Windows a backslash only escapes a
+// quote, so paths like C:\Games\x.ini survive.
+func splitArgsFor(s, goos string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case escaped:
+			if quote == '"' && !strings.ContainsRune("\\\"$`\n", r) {
+				cur.WriteRune('\\')
+			}
+			if r != '\n' {
+				cur.WriteRune(r)
+			}
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\\' && goos == "windows" && (i+1 == len(runes) || !strings.ContainsRune(`"'`, runes[i+1])):
+			cur.WriteRune(r)
+			inArg = true
+		case r == '\\':
+			escaped = true
+			inArg = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if escaped {
+		return nil, errors.New("trailing backslash")
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
+
+// gameEnv returns the environment for a launched game: the launcher's own
+// environment with the game's env map layered on top in a stable order.
+func gameEnv(env map[string]string) []string {
+	if len(env) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	result := os.Environ()
+	for _, k := range keys {
+		result = append(result, k+"="+env[k])
+	}
+	return result
+}
+
+// parseEnvLines parses the editor's "KEY=value" per-line env format. Blank
+// lines are skipped.
+func parseEnvLines(text string) (map[string]string, error) {
+	env := map[string]string{}
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: expected KEY=value", i+1)
+		}
+		env[key] = strings.TrimSpace(value)
+	}
+	if len(env) == 0 {
+		return nil, nil
+	}
+	return env, nil
+}
+
+// formatEnvLines is the inverse of parseEnvLines, with keys sorted.
+func formatEnvLines(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = k + "=" + env[k]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// resolveLaunch returns game with the config-wide launch settings folded in,
+// ready to hand to buildLaunchCmd.
+func (app *App) resolveLaunch(game Game) Game {
+	cfg := app.currentConfig()
+	game.Wrappers = mergeWrappers(cfg.Wrappers, game.Wrappers)
+	game.Hooks = mergeHooks(cfg.Hooks, game.Hooks)
+	if profile, ok := cfg.Emulators[game.Emulator]; ok && game.LaunchMethod == "emulator" {
+		game.emulatorProfile = &profile
+	}
+	return game
+}
+
+// mergeWrappers returns the global wrappers followed by the game's own,
+// dropping any game wrapper that repeats a global one.
+func mergeWrappers(global, own []string) []string {
+	if len(global) == 0 {
+		return own
+	}
+	merged := append([]string{}, global...)
+	for _, w := range own {
+		dup := false
+		for _, g := range global {
+			if strings.TrimSpace(g) == strings.TrimSpace(w) {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			merged = append(merged, w)
+		}
+	}
+	return merged
+}
+
+// wrapperArgv flattens wrapper command lines (each shell-quoted, e.g.
+// "gamescope -f --") into the argv prefix placed before the game executable.
+func wrapperArgv(wrappers []string) ([]string, error) {
+	var argv []string
+	for _, w := range wrappers {
+		parts, err := splitArgs(w)
+		if err != nil {
+			return nil, fmt.Errorf("wrapper %q: %w", w, err)
+		}
+		argv = append(argv, parts...)
+	}
+	return argv, nil
+}
+
+// checkWrappers verifies each wrapper a direct launch will use resolves to an
+// executable, so a missing gamemoderun fails loudly instead of the game
+// silently never starting. Protocol launches don't run wrappers themselves.
+func checkWrappers(game Game) error {
+	if isProtocolLaunch(game) {
+		return nil
+	}
+	for _, w := range game.Wrappers {
+		parts, err := splitArgs(w)
+		if err != nil {
+			return fmt.Errorf("wrapper %q: %w", w, err)
+		}
+		if len(parts) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(parts[0]); err != nil {
+			return fmt.Errorf("wrapper %q not found on PATH", parts[0])
+		}
+	}
+	return nil
+}
+
+// steamLaunchOptions renders wrappers and launch args as a Steam "Launch
+// Options" string (e.g. "gamemoderun mangohud %command% -novid"). Steam owns
+// the game process, so this is the only way wrappers reach a Steam game.
+func steamLaunchOptions(game Game) string {
+	parts := make([]string, 0, len(game.Wrappers)+2)
+	for _, w := range game.Wrappers {
+		if w = strings.TrimSpace(w); w != "" {
+			parts = append(parts, w)
+		}
+	}
+	parts = append(parts, "%command%")
+	if game.LaunchArgs != "" {
+		parts = append(parts, game.LaunchArgs)
+	}
+	return strings.Join(parts, " ")
+}
+
+// launchWrappersNote explains how a game's wrappers will (or won't) be
+// applied for protocol launches, or returns "" for direct launches.
+func launchWrappersNote(game Game) string {
+	if len(game.Wrappers) == 0 {
+		return ""
+	}
+	switch {
+	case game.LaunchMethod == "steam":
+		return "Steam starts the game itself — set its Launch Options in Steam to: " + steamLaunchOptions(game)
+	case isProtocolLaunch(game):
+		return "Wrappers cannot be applied to " + platformClients[game.LaunchMethod].Name + " launches"
+	}
+	return ""
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Steam / Epic launch args
+// ============================================================================
+
+func TestSteamAppID(t *testing.T) {
+	cases := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"steam://rungameid/413150", "413150", true},
+		{"steam://run/1091500", "1091500", true},
+		{"steam://run/1091500//-foo/", "1091500", true},
+		{"com.epicgames.launcher://apps/X", "", false},
+		{"/usr/bin/game", "", false},
+	}
+	for _, c := range cases {
+		got, ok := steamAppID(c.path)
+		if got != c.want || ok != c.wantOK {
+			t.Errorf("steamAppID(%q) = %q, %v; want %q, %v", c.path, got, ok, c.want, c.wantOK)
+		}
+	}
+}
+
+func TestLaunchURL_SteamWithArgs(t *testing.T) {
+	game := Game{GamePath: "steam://rungameid/1091500", LaunchMethod: "steam", LaunchArgs: "-skipStartScreen --launcher-skip"}
+	want := "steam://run/1091500//-skipStartScreen%20--launcher-skip/"
+	if got := launchURL(game); got != want {
+		t.Errorf("launchURL() = %q, want %q", got, want)
+	}
+}
+
+func TestLaunchURL_SteamEscapesCmdSeparator(t *testing.T) {
+	game := Game{GamePath: "steam://rungameid/1", LaunchMethod: "steam", LaunchArgs: "-a & calc"}
+	got := launchURL(game)
+	if strings.Contains(got, "&") || got != "steam://run/1//-a%20%26%20calc/" {
+		t.Errorf("launchURL() = %q, & must not reach cmd unescaped", got)
+	}
+	cmd := buildLaunchCmd(game, "windows")
+	if last := cmd.Args[len(cmd.Args)-1]; last != got {
+		t.Errorf("expected the escaped URL as start's argument, got %v", cmd.Args)
+	}
+}
+
+func TestLaunchURL_SteamWithoutArgsUnchanged(t *testing.T) {
+	game := Game{GamePath: "steam://rungameid/1091500", LaunchMethod: "steam"}
+	if got := launchURL(game); got != game.GamePath {
+		t.Errorf("launchURL() = %q, want unchanged %q", got, game.GamePath)
+	}
+}
+
+func TestLaunchURL_EpicIgnoresArgs(t *testing.T) {
+	game := Game{GamePath: "com.epicgames.launcher://apps/X", LaunchMethod: "epic", LaunchArgs: "-nosplash"}
+	if got := launchURL(game); got != game.GamePath {
+		t.Errorf("launchURL() = %q, want unchanged %q", got, game.GamePath)
+	}
+}
+
+func TestLaunchArgsWarning(t *testing.T) {
+	cases := []struct {
+		name string
+		game Game
+		warn bool
+	}{
+		{"steam no args", Game{LaunchMethod: "steam", GamePath: "steam://rungameid/1"}, false},
+		{"steam with args", Game{LaunchMethod: "steam", GamePath: "steam://rungameid/1", LaunchArgs: "-x"}, false},
+		{"steam bad url", Game{LaunchMethod: "steam", GamePath: "steam://open/games", LaunchArgs: "-x"}, true},
+		{"epic with args", Game{LaunchMethod: "epic", GamePath: "com.epicgames.launcher://apps/X", LaunchArgs: "-x"}, true},
+		{"direct with args", Game{LaunchMethod: "direct", GamePath: "/usr/bin/game", LaunchArgs: "-x"}, false},
+	}
+	for _, c := range cases {
+		if got := launchArgsWarning(c.game); (got != "") != c.warn {
+			t.Errorf("%s: launchArgsWarning() = %q, want warning=%v", c.name, got, c.warn)
+		}
+	}
+}
+
+func TestBuildLaunchCmd_Steam_WithArgs(t *testing.T) {
+	game := Game{GamePath: "steam://rungameid/413150", LaunchMethod: "steam", LaunchArgs: "-skipStartScreen"}
+	for _, goos := range []string{"darwin", "windows", "linux"} {
+		cmd := buildLaunchCmd(game, goos)
+		last := cmd.Args[len(cmd.Args)-1]
+		if !strings.HasPrefix(last, "steam://run/413150//") || !strings.Contains(last, "-skipStartScreen") {
+			t.Errorf("%s: expected steam://run URL carrying args, got %v", goos, cmd.Args)
+		}
+	}
+}
+
+// ============================================================================
+// splitArgs / env / working dir
+// ============================================================================
+
+func TestSplitArgs(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"-fullscreen -nosound", []string{"-fullscreen", "-nosound"}},
+		{`--config "My Settings.ini"`, []string{"--config", "My Settings.ini"}},
+		{`--name 'it''s'`, []string{"--name", "its"}},
+		{`'a "b" c'`, []string{`a "b" c`}},
+		{`"a \"b\" \c"`, []string{`a "b" \c`}},
+		{`path\ with\ spaces`, []string{"path with spaces"}},
+		{`--empty ""`, []string{"--empty", ""}},
+		{"  -a\t-b  ", []string{"-a", "-b"}},
+	}
+	for _, c := range cases {
+		got, err := splitArgsFor(c.in, "linux")
+		if err != nil {
+			t.Errorf("splitArgs(%q) error: %v", c.in, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(c.want, "|") || len(got) != len(c.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestSplitArgs_Windows(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{`-config C:\Games\x.ini`, []string{"-config", `C:\Games\x.ini`}},
+		{`--dir "C:\Program Files\Game"`, []string{"--dir", `C:\Program Files\Game`}},
+		{`--name "say \"hi\""`, []string{"--name", `say "hi"`}},
+		{`C:\dir\`, []string{`C:\dir\`}},
+	}
+	for _, c := range cases {
+		got, err := splitArgsFor(c.in, "windows")
+		if err != nil {
+			t.Errorf("splitArgsFor(%q, windows) error: %v", c.in, err)
+			continue
+		}
+		if strings.Join(got, "|") != strings.Join(c.want, "|") || len(got) != len(c.want) {
+			t.Errorf("splitArgsFor(%q, windows) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestSplitArgs_Errors(t *testing.T) {
+	for _, in := range []string{`"unterminated`, `'unterminated`, `trailing\`} {
+		if _, err := splitArgsFor(in, "linux"); err == nil {
+			t.Errorf("splitArgs(%q): expected error", in)
+		}
+	}
+}
+
+func TestBuildLaunchCmd_Direct_QuotedArgs(t *testing.T) {
+	game := Game{GamePath: "/usr/bin/mygame", LaunchMethod: "direct", LaunchArgs: `--config "My Settings.ini"`}
+	cmd := buildLaunchCmd(game, "linux")
+	if len(cmd.Args) != 3 || cmd.Args[2] != "My Settings.ini" {
+		t.Errorf("expected quoted arg kept together, got %q", cmd.Args)
+	}
+}
+
+func TestBuildLaunchCmd_Direct_EnvAndWorkingDir(t *testing.T) {
+	game := Game{
+		GamePath:     "/usr/bin/mygame",
+		LaunchMethod: "direct",
+		Env:          map[string]string{"PROTON_LOG": "1", "DXVK_HUD": "fps"},
+		WorkingDir:   "/tmp",
+	}
+	cmd := buildLaunchCmd(game, "linux")
+	if cmd.Dir != "/tmp" {
+		t.Errorf("expected Dir /tmp, got %q", cmd.Dir)
+	}
+	n := len(cmd.Env)
+	if n < 2 || cmd.Env[n-2] != "DXVK_HUD=fps" || cmd.Env[n-1] != "PROTON_LOG=1" {
+		t.Errorf("expected game env appended in sorted order, got tail %q", cmd.Env[max(0, n-2):])
+	}
+}
+
+func TestBuildLaunchCmd_Direct_NoEnvInheritsParent(t *testing.T) {
+	cmd := buildLaunchCmd(Game{GamePath: "/usr/bin/mygame", LaunchMethod: "direct"}, "linux")
+	if cmd.Env != nil {
+		t.Errorf("expected nil Env (inherit) without an env map, got %d entries", len(cmd.Env))
+	}
+}
+
+func TestLaunchEnvWarning(t *testing.T) {
+	env := map[string]string{"A": "1"}
+	if w := launchEnvWarning(Game{LaunchMethod: "direct", Env: env}); w != "" {
+		t.Errorf("direct launch should not warn about env, got %q", w)
+	}
+	if w := launchEnvWarning(Game{LaunchMethod: "steam", Env: env}); w == "" {
+		t.Error("steam launch should warn that env is not applied")
+	}
+	if w := launchEnvWarning(Game{LaunchMethod: "epic", WorkingDir: "/tmp"}); w == "" {
+		t.Error("epic launch should warn that working dir is not applied")
+	}
+}
+
+func TestParseEnvLines_RoundTrip(t *testing.T) {
+	env, err := parseEnvLines("PROTON_LOG=1\n\n DXVK_HUD = fps,memory \nEMPTY=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env["PROTON_LOG"] != "1" || env["DXVK_HUD"] != "fps,memory" || env["EMPTY"] != "" {
+		t.Errorf("unexpected env: %q", env)
+	}
+	if got := formatEnvLines(map[string]string{"B": "2", "A": "1"}); got != "A=1\nB=2" {
+		t.Errorf("formatEnvLines() = %q", got)
+	}
+}
+
+func TestParseEnvLines_Errors(t *testing.T) {
+	for _, in := range []string{"NOEQUALS", "=value", "BAD KEY=1"} {
+		if _, err := parseEnvLines(in); err == nil {
+			t.Errorf("parseEnvLines(%q): expected error", in)
+		}
+	}
+	if env, err := parseEnvLines("  \n"); err != nil || env != nil {
+		t.Errorf("blank input should give nil map, got %v, %v", env, err)
+	}
+}
+
+// ============================================================================
+// wrappers
+// ============================================================================
+
+func TestMergeWrappers(t *testing.T) {
+	got := mergeWrappers([]string{"gamemoderun"}, []string{" gamemoderun ", "mangohud"})
+	if strings.Join(got, "|") != "gamemoderun|mangohud" {
+		t.Errorf("mergeWrappers() = %q", got)
+	}
+	own := []string{"mangohud"}
+	if got := mergeWrappers(nil, own); len(got) != 1 || got[0] != "mangohud" {
+		t.Errorf("mergeWrappers(nil, own) = %q", got)
+	}
+}
+
+func TestResolveLaunch_AppliesGlobalWrappers(t *testing.T) {
+	app := appWithGames(nil)
+	app.config.Wrappers = []string{"gamemoderun"}
+	got := app.resolveLaunch(Game{GamePath: "/usr/bin/game", Wrappers: []string{"mangohud"}})
+	if strings.Join(got.Wrappers, "|") != "gamemoderun|mangohud" {
+		t.Errorf("expected global then game wrappers, got %q", got.Wrappers)
+	}
+}
+
+func TestBuildLaunchCmd_Direct_Wrappers(t *testing.T) {
+	game := Game{
+		GamePath:     "/usr/bin/mygame",
+		LaunchMethod: "direct",
+		LaunchArgs:   "-novid",
+		Wrappers:     []string{"gamemoderun", "gamescope -f --"},
+	}
+	cmd := buildLaunchCmd(game, "linux")
+	want := "gamemoderun|gamescope|-f|--|/usr/bin/mygame|-novid"
+	if got := strings.Join(cmd.Args, "|"); got != want {
+		t.Errorf("buildLaunchCmd args = %q, want %q", got, want)
+	}
+}
+
+func TestBuildLaunchCmd_Steam_IgnoresWrappers(t *testing.T) {
+	game := Game{GamePath: "steam://rungameid/1", LaunchMethod: "steam", Wrappers: []string{"gamemoderun"}}
+	cmd := buildLaunchCmd(game, "linux")
+	if !strings.HasSuffix(cmd.Path, "xdg-open") {
+		t.Errorf("steam launch should still go through xdg-open, got %v", cmd.Args)
+	}
+}
+
+func TestCheckWrappers(t *testing.T) {
+	if err := checkWrappers(Game{LaunchMethod: "direct", Wrappers: []string{"definitely-not-a-real-wrapper-xyz"}}); err == nil {
+		t.Error("expected error for wrapper missing from PATH")
+	}
+	if err := checkWrappers(Game{LaunchMethod: "steam", Wrappers: []string{"definitely-not-a-real-wrapper-xyz"}}); err != nil {
+		t.Errorf("steam launches don't run wrappers, expected no error, got %v", err)
+	}
+	if err := checkWrappers(Game{LaunchMethod: "direct", Wrappers: []string{`"unterminated`}}); err == nil {
+		t.Error("expected error for unparseable wrapper")
+	}
+	if runtime.GOOS != "windows" {
+		if err := checkWrappers(Game{LaunchMethod: "direct", Wrappers: []string{"sh -c"}}); err != nil {
+			t.Errorf("expected sh to be found on PATH, got %v", err)
+		}
+	}
+}
+
+func TestSteamLaunchOptions(t *testing.T) {
+	game := Game{Wrappers: []string{"gamemoderun", "mangohud"}, LaunchArgs: "-novid"}
+	if got := steamLaunchOptions(game); got != "gamemoderun mangohud %command% -novid" {
+		t.Errorf("steamLaunchOptions() = %q", got)
+	}
+	if note := launchWrappersNote(Game{LaunchMethod: "steam", Wrappers: []string{"gamemoderun"}}); !strings.Contains(note, "gamemoderun %command%") {
+		t.Errorf("expected steam note to include launch options, got %q", note)
+	}
+	if note := launchWrappersNote(Game{LaunchMethod: "direct", Wrappers: []string{"gamemoderun"}}); note != "" {
+		t.Errorf("direct launches apply wrappers themselves, got note %q", note)
+	}
+}
+
+func TestLaunchGameByStruct_MissingWrapperAborts(t *testing.T) {
+	game := Game{
+		GameName:     "Wrapped",
+		GamePath:     "/absolutely/does/not/exist/game",
+		LaunchMethod: "direct",
+		Wrappers:     []string{"definitely-not-a-real-wrapper-xyz"},
+	}
+	app := appWithGames([]Game{game})
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	app.launchGameByStruct(game)
+
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyLaunchFailed || !strings.Contains(entries[0].Detail, "not found") {
+		t.Errorf("expected the aborted launch recorded as failed, got %+v", entries)
+	}
+	if _, ok := app.lastLaunchTime[game.GameName]; !ok {
+		t.Error("an aborted launch should use up its window like any failed one")
+	}
+}
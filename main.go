@@ -3,6 +3,7 @@ package main
 import (
 	"bytes"
 	_ "embed"
+	"flag"
 	"fmt"
 	"image"
 	"image/color"
@@ -15,13 +16,12 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/driver/desktop"
-	"github.com/fsnotify/fsnotify"
 	"github.com/shirou/gopsutil/v4/process"
-	"gopkg.in/yaml.v3"
 )
 
 //go:embed icon.png
@@ -34,17 +34,48 @@ type Schedule struct {
 }
 
 type Game struct {
+	ID           string     `yaml:"id,omitempty"` // stable identifier, assigned on load; survives renames
 	GameName     string     `yaml:"game_name"`
 	GamePath     string     `yaml:"game_path"`
-	LaunchMethod string     `yaml:"launch_method"` // "steam", "gog", "epic", "direct"
+	LaunchMethod string     `yaml:"launch_method"` // "steam", "epic", "battlenet", "ea", "ubisoft", "direct", "wine", "flatpak", "desktop", "emulator"
 	LaunchArgs   string     `yaml:"launch_args"`
 	Schedules    []Schedule `yaml:"schedules"`
 	Enabled      bool       `yaml:"enabled"`
+
+	Env        map[string]string `yaml:"env,omitempty"`         // extra environment for direct launches, e.g. PROTON_LOG: "1"
+	WorkingDir string            `yaml:"working_dir,omitempty"` // directory the game is started in
+	Wrappers   []string          `yaml:"wrappers,omitempty"`    // commands prefixed to the launch, e.g. "gamescope -f --"
+	Hooks      Hooks             `yaml:"hooks,omitempty"`
+
+	// For the "wine" method: the wine or proton binary (default "wine") and
+	// the prefix it runs in (WINEPREFIX, or STEAM_COMPAT_DATA_PATH for proton).
+	WineBinary string `yaml:"wine_binary,omitempty"`
+	WinePrefix string `yaml:"wine_prefix,omitempty"`
+
+	// For the "emulator" method: the name of a profile in Config.Emulators.
+	// game_path is then the ROM.
+	Emulator string `yaml:"emulator,omitempty"`
+
+	emulatorProfile *Emulator // looked up by resolveLaunch
 }
 
 type Config struct {
-	Games     []Game `yaml:"games"`
-	BootDelay int    `yaml:"boot_delay"`
+	Version   int      `yaml:"version"`           // schema version; older files are upgraded by configMigrations
+	Include   []string `yaml:"include,omitempty"` // files merged in before this one, relative to it; globs allowed
+	Games     []Game   `yaml:"games"`
+	BootDelay int      `yaml:"boot_delay"`
+	Wrappers  []string `yaml:"wrappers,omitempty"` // applied to every game, before its own wrappers
+	Hooks     Hooks    `yaml:"hooks,omitempty"`    // run around every game, outside its own hooks
+
+	Emulators map[string]Emulator `yaml:"emulators,omitempty"` // profiles for the "emulator" launch method, by name
+
+	LaunchVerifyTimeout int `yaml:"launch_verify_timeout"` // seconds to wait for a game's process to appear; 0 disables
+	LaunchRetries       int `yaml:"launch_retries"`        // extra attempts when a launch can't be verified
+	CrashWindow         int `yaml:"crash_window"`          // seconds; a failing exit sooner than this counts as a crash
+
+	StartClients       bool `yaml:"start_clients"`        // start Steam/Epic/etc. and wait for it before sending a game URL
+	ClientReadyTimeout int  `yaml:"client_ready_timeout"` // seconds to wait for a started client to become ready
+	ClientSettleTime   int  `yaml:"client_settle_time"`   // seconds a client's process must stay up to count as ready
 
 	// Legacy fields for backwards compatibility
 	GamePath   string `yaml:"game_path,omitempty"`
@@ -55,28 +86,90 @@ type Config struct {
 }
 
 type App struct {
-	config             *Config
-	configPath         string
-	logFile            *os.File
+	configPath  string
+	logFile     *os.File
+	ui          *GameManagerUI
+	desk        desktop.App
+	historyPath string
+	gameLogDir  string            // where direct launches' output is captured; empty discards it
+	overrides   []settingOverride // from the environment and command line; set once at startup
+	saveMu      sync.Mutex        // serializes writes to config.yaml and its backups
+
+	// mu guards the fields below, which are shared between goroutines; use
+	// the accessors in state.go.
+	mu                 sync.Mutex
+	config             *Config         // the active snapshot; replaced, never modified
+	fileConfig         *Config         // config as the files give it, before overrides
+	includes           *configIncludes // the files merged into config, or nil
 	lastLaunchTime     map[string]time.Time
-	ui                 *GameManagerUI
-	desk               desktop.App
 	cancelLaunch       func()
 	pendingGameName    string
 	pendingSecondsLeft int
+	launchFailure      string            // last launch failure or crash, shown in the tray until the next successful launch
+	gameIssues         map[string]string // per-game launch failure or crash, shown in the manager window
+	configErrors       []ConfigError     // problems found in config.yaml, shown in the manager window
+	configRejected     bool              // configErrors stopped config.yaml loading; the last good config is in use
+	lastWrittenConfig  []byte            // what the launcher last wrote to config.yaml, so the watcher can skip it
+	readOnly           bool              // never write the config files (--dry-run)
 }
 
 func main() {
+	dryRun := flag.Bool("dry-run", false, "print what each game would launch right now, without starting anything")
+	configFlag := flag.String("config", "", "path to config.yaml (also $FRICTIONLESS_CONFIG)")
+	var flagOverrides []settingOverride
+	registerSettingFlags(flag.CommandLine, &flagOverrides)
+	flag.Usage = usage
+	flag.Parse()
+
+	// The only command is "config path"; flags may also follow it.
+	command := flag.Args()
+	if len(command) > 0 {
+		if len(command) < 2 || command[0] != "config" || command[1] != "path" {
+			usage()
+			os.Exit(2)
+		}
+		flag.CommandLine.Parse(command[2:])
+		if flag.NArg() > 0 {
+			usage()
+			os.Exit(2)
+		}
+	}
+
+	exe, _ := os.Executable()
+	configPath, configSource := locateConfig(*configFlag, exe)
 	a := &App{
-		configPath:     getConfigPath(),
+		configPath:     configPath,
 		lastLaunchTime: make(map[string]time.Time),
+		historyPath:    filepath.Join(appLogDir(), historyFileName),
+		gameLogDir:     filepath.Join(appLogDir(), "games"),
+		overrides:      settingOverrides(flagOverrides),
+	}
+
+	if len(command) > 0 {
+		a.printConfigPaths(os.Stdout, configSource)
+		return
 	}
+	if *dryRun {
+		// Log to stderr so the plan on stdout stays readable.
+		a.readOnly = true
+		a.loadConfig()
+		a.dryRun(os.Stdout)
+		return
+	}
+	a.configPath = ensureConfigDir(configPath, configSource, exe)
 
+	moved := moveLegacyState(legacyLogDir(), appLogDir())
 	a.setupLogging()
 	defer a.closeLogFile()
+	if len(moved) > 0 {
+		log.Printf("Moved %s from %s to %s", strings.Join(moved, " and "), legacyLogDir(), appLogDir())
+	}
 
+	log.Printf("Config path: %s (%s)", a.configPath, configSource)
+	for _, o := range a.overrides {
+		log.Printf("Setting override: %s = %s (%s)", o.Key, o.Value, o.Source)
+	}
 	a.loadConfig()
-	log.Printf("Config path: %s", a.configPath)
 	a.ui = newGameManagerUI(a)
 
 	go a.scheduleMonitor()
@@ -84,7 +177,7 @@ func main() {
 
 	setupDockBehavior(a.ui.fyneApp, func() {
 		bootLaunched := false
-		for _, game := range a.config.Games {
+		for _, game := range a.currentConfig().Games {
 			if game.Enabled && a.shouldLaunchGame(game) {
 				log.Printf("Boot within schedule window for %s — queuing auto-launch", game.GameName)
 				go a.autoLaunchGameByName(game)
@@ -116,13 +209,15 @@ func (app *App) buildTrayMenu(desk desktop.App) *fyne.Menu {
 
 	items := []*fyne.MenuItem{}
 
-	if app.cancelLaunch != nil {
-		label := fmt.Sprintf("⏳ %s launching in %ds... — Cancel", app.pendingGameName, app.pendingSecondsLeft)
-		cancelItem := fyne.NewMenuItem(label, func() {
-			if app.cancelLaunch != nil {
-				app.cancelLaunch()
-			}
-		})
+	if failure := app.launchFailureMessage(); failure != "" {
+		failed := fyne.NewMenuItem("⚠️ "+failure, nil)
+		failed.Disabled = true
+		items = append(items, failed, fyne.NewMenuItemSeparator())
+	}
+
+	if pending, ok := app.pending(); ok {
+		label := fmt.Sprintf("⏳ %s launching in %ds... — Cancel", pending.GameName, pending.SecondsLeft)
+		cancelItem := fyne.NewMenuItem(label, pending.Cancel)
 		items = append(items, cancelItem)
 	} else {
 		upcoming := app.nextScheduledGames(3)
@@ -160,7 +255,7 @@ func (app *App) nextScheduledGames(n int) []Game {
 		next time.Time
 	}
 	var candidates []candidate
-	for _, game := range app.config.Games {
+	for _, game := range app.currentConfig().Games {
 		if !game.Enabled {
 			continue
 		}
@@ -228,12 +323,27 @@ func (app *App) nextScheduleLabelAt(game Game, now time.Time) string {
 	return t.Format("Mon 15:04")
 }
 
-func (app *App) loadConfig() {
-	app.config = &Config{
-		BootDelay: 10,
+// defaultConfig returns the settings used for anything config.yaml leaves out.
+func defaultConfig() *Config {
+	return &Config{
+		Version:             currentConfigVersion,
+		BootDelay:           10,
+		LaunchVerifyTimeout: 60,
+		LaunchRetries:       1,
+		CrashWindow:         60,
+		ClientReadyTimeout:  120,
+		ClientSettleTime:    10,
 	}
+}
 
+func (app *App) loadConfig() {
 	if _, err := os.Stat(app.configPath); os.IsNotExist(err) {
+		app.setConfig(defaultConfig())
+		app.setConfigErrors(nil, false)
+		if app.readOnly {
+			log.Println("No config found, using the defaults")
+			return
+		}
 		log.Println("No config found, creating empty config.yaml")
 		app.saveConfig()
 		return
@@ -242,62 +352,71 @@ func (app *App) loadConfig() {
 	data, err := os.ReadFile(app.configPath)
 	if err != nil {
 		log.Printf("Error reading config: %v", err)
-		log.Println("Using default config due to read error")
+		if app.currentConfig() == nil {
+			log.Println("Using default config due to read error")
+			app.setConfig(defaultConfig())
+		}
 		return
 	}
 
-	if err := yaml.Unmarshal(data, app.config); err != nil {
-		log.Printf("Error parsing config: %v", err)
-		log.Println("Config file has invalid YAML, using defaults - please check your config.yaml file for syntax errors")
+	cfg := defaultConfig()
+	changes, errs, ok, inc := parseLayeredConfig(app.configPath, data, cfg)
+	if !ok {
+		app.rejectConfig(errs)
 		return
 	}
-
-	// Migrate legacy single-game config to new format if needed
-	if app.config.GamePath != "" && len(app.config.Games) == 0 {
-		log.Println("Migrating legacy config format to new multi-game format")
-		legacyGame := Game{
-			GameName:     app.config.GameName,
-			GamePath:     app.config.GamePath,
-			LaunchMethod: "direct",
-			LaunchArgs:   app.config.LaunchArgs,
-			Enabled:      app.config.Enabled,
-			Schedules:    []Schedule{},
-		}
-
-		// Convert old schedule string to new format
-		if app.config.Schedule == "always" {
-			legacyGame.Schedules = []Schedule{
-				{
-					Days:      []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"},
-					StartTime: "00:00",
-					EndTime:   "23:59",
-				},
-			}
+	ids := assignGameIDs(cfg)
+	for _, c := range ids {
+		log.Printf("Config: %s", c)
+	}
+	app.setLoadedConfig(cfg, inc)
+	app.setConfigErrors(errs, false)
+	if len(errs) > 0 {
+		for _, e := range errs {
+			log.Printf("Config problem: %v", e)
+		}
+		if !app.readOnly {
+			sendNativeNotification("Frictionless", fmt.Sprintf("config.yaml has %d problem(s): %v", len(errs), errs[0]))
 		}
+	}
 
-		app.config.Games = []Game{legacyGame}
-		// Clear legacy fields
-		app.config.GamePath = ""
-		app.config.GameName = ""
-		app.config.LaunchArgs = ""
-		app.config.Schedule = ""
+	for _, c := range changes {
+		log.Printf("Config migration: %s", c)
+	}
+	if app.readOnly {
+		if len(changes) > 0 || len(ids) > 0 {
+			log.Println("Not saving the migrated config or new game IDs (read-only)")
+		}
+	} else if len(changes) > 0 {
+		if backup, err := backupConfigFile(app.configPath, data, time.Now()); err != nil {
+			log.Printf("Error backing up config before migration, leaving it unchanged: %v", err)
+		} else {
+			log.Printf("Backed up the old config to %s", backup)
+			app.saveConfig()
+		}
+	} else if len(ids) > 0 {
 		app.saveConfig()
 	}
 
-	log.Printf("Loaded config with %d game(s)", len(app.config.Games))
+	if inc != nil {
+		log.Printf("Loaded config with %d game(s), merged with %d other file(s)", len(cfg.Games), len(inc.Files))
+	} else {
+		log.Printf("Loaded config with %d game(s)", len(cfg.Games))
+	}
 	app.warnScheduleOverlaps()
 }
 
 func (app *App) warnScheduleOverlaps() {
-	for i := 0; i < len(app.config.Games); i++ {
-		for _, si := range app.config.Games[i].Schedules {
+	games := app.currentConfig().Games
+	for i := 0; i < len(games); i++ {
+		for _, si := range games[i].Schedules {
 			for _, di := range si.Days {
-				for j := i + 1; j < len(app.config.Games); j++ {
-					for _, sj := range app.config.Games[j].Schedules {
+				for j := i + 1; j < len(games); j++ {
+					for _, sj := range games[j].Schedules {
 						for _, dj := range sj.Days {
 							if strings.EqualFold(di, dj) && si.StartTime < sj.EndTime && sj.StartTime < si.EndTime {
 								log.Printf("WARNING: schedule overlap between %q and %q on %s (%s-%s vs %s-%s)",
-									app.config.Games[i].GameName, app.config.Games[j].GameName,
+									games[i].GameName, games[j].GameName,
 									di, si.StartTime, si.EndTime, sj.StartTime, sj.EndTime)
 							}
 						}
@@ -309,18 +428,38 @@ func (app *App) warnScheduleOverlaps() {
 }
 
 func (app *App) saveConfig() {
-	data, err := yaml.Marshal(app.config)
-	if err != nil {
-		log.Printf("Error marshaling config: %v", err)
+	if err := app.patchConfigFile(); err != nil {
+		log.Printf("Error saving config: %v", err)
 		return
 	}
+	app.refreshTrayMenu()
+}
 
-	if err := os.WriteFile(app.configPath, data, 0644); err != nil {
-		log.Printf("Error saving config: %v", err)
-		return
+// patchConfigFile writes the current config to config.yaml, patching the
+// existing file so hand-written comments and layout survive.
+func (app *App) patchConfigFile() error {
+	app.saveMu.Lock()
+	defer app.saveMu.Unlock()
+	original, _ := os.ReadFile(app.configPath)
+	var data []byte
+	var err error
+	if inc := app.includedFiles(); inc != nil {
+		data, err = marshalLayeredConfig(original, app.configToSave(), inc.Base)
+	} else {
+		data, err = marshalConfig(original, app.configToSave())
+	}
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
 	}
+	return app.replaceConfigFile(data)
+}
 
-	app.refreshTrayMenu()
+// marshalConfig encodes cfg laid over original, the config file it replaces.
+func marshalConfig(original []byte, cfg *Config) ([]byte, error) {
+	// The version is always written, so it isn't given a default.
+	defaults := defaultConfig()
+	defaults.Version = 0
+	return marshalPreservingYAML(original, cfg, defaults)
 }
 
 func fadedIcon(src []byte, alpha uint8) ([]byte, error) {
@@ -450,46 +589,6 @@ func (app *App) getForegroundAppName() (string, error) {
 	return name, nil
 }
 
-func (app *App) watchConfigFile() {
-	watcher, err := fsnotify.NewWatcher()
-	if err != nil {
-		log.Printf("Error creating file watcher: %v", err)
-		return
-	}
-	defer watcher.Close()
-
-	// Watch the config file
-	if err := watcher.Add(app.configPath); err != nil {
-		log.Printf("Error watching config file: %v", err)
-		return
-	}
-
-	log.Printf("Watching config file for changes: %s", app.configPath)
-
-	for {
-		select {
-		case event, ok := <-watcher.Events:
-			if !ok {
-				return
-			}
-
-			// Only care about write events
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				log.Printf("Config file changed, reloading...")
-				app.loadConfig()
-				log.Println("Config reloaded successfully")
-				app.refreshTrayMenu()
-			}
-
-		case err, ok := <-watcher.Errors:
-			if !ok {
-				return
-			}
-			log.Printf("Watcher error: %v", err)
-		}
-	}
-}
-
 func (app *App) scheduleMonitor() {
 	ticker := time.NewTicker(1 * time.Minute)
 	defer ticker.Stop()
@@ -508,7 +607,7 @@ func (app *App) scheduleMonitor() {
 			lastChecked = now
 
 			// Check each enabled game's schedule
-			for _, game := range app.config.Games {
+			for _, game := range app.currentConfig().Games {
 				if !game.Enabled {
 					continue
 				}
@@ -540,17 +639,14 @@ func (app *App) scheduleMonitor() {
 func (app *App) autoLaunchGameByName(game Game) {
 	fgApp, _ := app.getForegroundAppName()
 
+	bootDelay := app.currentConfig().BootDelay
 	cancelled := make(chan struct{})
-	app.pendingGameName = game.GameName
-	app.pendingSecondsLeft = app.config.BootDelay
-	app.cancelLaunch = func() {
-		select {
-		case <-cancelled:
-		default:
-			close(cancelled)
-		}
-	}
-	app.refreshTrayMenu()
+	var cancelOnce sync.Once
+	app.setPending(pendingLaunch{
+		GameName:    game.GameName,
+		SecondsLeft: bootDelay,
+		Cancel:      func() { cancelOnce.Do(func() { close(cancelled) }) },
+	})
 	app.startIconPulse(cancelled)
 
 	// Tick down the tray label every second
@@ -562,8 +658,7 @@ func (app *App) autoLaunchGameByName(game Game) {
 			case <-cancelled:
 				return
 			case <-ticker.C:
-				app.pendingSecondsLeft--
-				if app.pendingSecondsLeft <= 0 {
+				if app.tickPending() <= 0 {
 					return
 				}
 				app.refreshTrayMenu()
@@ -571,16 +666,11 @@ func (app *App) autoLaunchGameByName(game Game) {
 		}
 	}()
 
-	cleanup := func() {
-		app.cancelLaunch = nil
-		app.pendingGameName = ""
-		app.pendingSecondsLeft = 0
-		app.refreshTrayMenu()
-	}
+	cleanup := app.clearPending
 
 	if fgApp != "" {
-		log.Printf("Foreground app detected (%s) — notifying, launching %s in %ds unless cancelled via tray", fgApp, game.GameName, app.config.BootDelay)
-		sendNativeNotification("Frictionless", fmt.Sprintf("%s is launching in %d seconds — cancel from the menu bar if needed", game.GameName, app.config.BootDelay))
+		log.Printf("Foreground app detected (%s) — notifying, launching %s in %ds unless cancelled via tray", fgApp, game.GameName, bootDelay)
+		sendNativeNotification("Frictionless", fmt.Sprintf("%s is launching in %d seconds — cancel from the menu bar if needed", game.GameName, bootDelay))
 
 		select {
 		case <-cancelled:
@@ -588,7 +678,7 @@ func (app *App) autoLaunchGameByName(game Game) {
 			cleanup()
 			app.recordLaunch(game)
 			return
-		case <-time.After(time.Duration(app.config.BootDelay) * time.Second):
+		case <-time.After(time.Duration(bootDelay) * time.Second):
 		}
 
 		cleanup()
@@ -597,10 +687,10 @@ func (app *App) autoLaunchGameByName(game Game) {
 	}
 
 	log.Printf("Showing launch countdown for %s", game.GameName)
-	sendNativeNotification("Frictionless", fmt.Sprintf("Launching %s in %d seconds", game.GameName, app.config.BootDelay))
+	sendNativeNotification("Frictionless", fmt.Sprintf("Launching %s in %d seconds", game.GameName, bootDelay))
 
 	done := make(chan bool, 1)
-	app.ui.showLaunchCountdown(game.GameName, app.config.BootDelay, func(launch bool) {
+	app.ui.showLaunchCountdown(game.GameName, bootDelay, func(launch bool) {
 		done <- launch
 	})
 
@@ -626,21 +716,47 @@ func (app *App) autoLaunchGameByName(game Game) {
 // current OS. It does not start the command.
 func buildLaunchCmd(game Game, goos string) *exec.Cmd {
 	switch game.LaunchMethod {
-	case "steam", "epic":
+	case "steam", "epic", "battlenet", "ea", "ubisoft":
+		target := launchURL(game)
 		switch goos {
 		case "darwin":
-			return exec.Command("open", "-g", game.GamePath)
+			return exec.Command("open", "-g", target)
 		case "windows":
-			return exec.Command("cmd", "/c", "start", game.GamePath)
+			return exec.Command("cmd", "/c", "start", target)
 		default:
-			return exec.Command("xdg-open", game.GamePath)
+			if game.LaunchMethod == "steam" {
+				// A sandboxed Steam may not have registered the steam://
+				// handler, so hand it the URL directly.
+				switch detectSteamPackaging() {
+				case packagingFlatpak:
+					return exec.Command("flatpak", "run", steamFlatpakID, target)
+				case packagingSnap:
+					return exec.Command("snap", "run", "steam", target)
+				}
+			}
+			return exec.Command("xdg-open", target)
 		}
-	default: // "direct" and unknown methods
-		var args []string
-		if game.LaunchArgs != "" {
-			args = strings.Fields(game.LaunchArgs)
+	case "flatpak":
+		return flatpakRunCmd(game)
+	case "desktop":
+		return desktopLaunchCmd(game)
+	case "emulator":
+		return emulatorLaunchCmd(game)
+	case "wine":
+		program, env := wineCommand(game)
+		cmd := execGameCmd(game, program)
+		cmd.Env = gameEnv(env)
+		cmd.Dir = game.WorkingDir
+		if cmd.Dir == "" {
+			// Windows games commonly load assets relative to their own folder.
+			cmd.Dir = filepath.Dir(game.GamePath)
 		}
-		return exec.Command(game.GamePath, args...)
+		return cmd
+	default: // "direct" and unknown methods
+		cmd := execGameCmd(game, []string{game.GamePath})
+		cmd.Env = gameEnv(game.Env)
+		cmd.Dir = game.WorkingDir
+		return cmd
 	}
 }
 
@@ -650,43 +766,144 @@ func (app *App) launchGameByStruct(game Game) {
 		return
 	}
 
-	log.Printf("Launching %s via %s", game.GameName, game.LaunchMethod)
+	cfg := app.currentConfig()
+	game = app.resolveLaunch(game)
 
-	switch game.LaunchMethod {
-	case "steam":
-		if !app.isPlatformRunning("steam") {
-			log.Printf("Warning: Steam does not appear to be running — it will launch first, adding delay")
+	log.Printf("Launching %s via %s", game.GameName, game.LaunchMethod)
+	for _, warning := range []string{launchArgsWarning(game), launchEnvWarning(game), launchWrappersNote(game)} {
+		if warning != "" {
+			log.Printf("Warning: %s: %s", game.GameName, warning)
 		}
-	case "epic":
-		if !app.isPlatformRunning("epic") {
-			log.Printf("Warning: Epic Games Launcher does not appear to be running — it will launch first, adding delay")
+	}
+	if err := checkWrappers(game); err != nil {
+		log.Printf("Not launching %s: %v", game.GameName, err)
+		app.launchFailed(game, err)
+		return
+	}
+	if err := runHooks(game, hookPreLaunch, game.Hooks.PreLaunch); err != nil {
+		log.Printf("Not launching %s: %v", game.GameName, err)
+		app.launchFailed(game, err)
+		return
+	}
+
+	// From here on the pre-launch hooks have run, so a failure runs the
+	// post-session ones to undo them.
+	if client, ok := platformClients[game.LaunchMethod]; ok && !app.isPlatformRunning(game.LaunchMethod) {
+		if !cfg.StartClients {
+			log.Printf("Warning: %s does not appear to be running — it will launch first, adding delay", client.Name)
+		} else if err := app.startPlatformClient(game.LaunchMethod); err != nil {
+			log.Printf("Error launching %s: %v", game.GameName, err)
+			runHooks(game, hookPostSession, game.Hooks.PostSession)
+			app.launchFailed(game, err)
+			return
 		}
 	}
 
-	cmd := buildLaunchCmd(game, runtime.GOOS)
-	if err := cmd.Start(); err != nil {
-		log.Printf("Error launching game: %v", err)
-		return
+	var err error
+	attempts := 1 + max(cfg.LaunchRetries, 0)
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			log.Printf("Retrying launch of %s (attempt %d of %d)", game.GameName, attempt, attempts)
+		}
+		var proc *launchedProcess
+		cmd := buildLaunchCmd(game, runtime.GOOS)
+		closeOutput := app.captureOutput(game, cmd)
+		proc, err = startLaunch(game, cmd)
+		closeOutput()
+		if err == nil {
+			timeout := time.Duration(cfg.LaunchVerifyTimeout) * time.Second
+			if err = verifyLaunch(proc, sessionProbe(game), timeout); err == nil {
+				app.recordLaunch(game)
+				app.recordHistory(game, historyLaunched, "")
+				app.setLaunchFailure("")
+				app.setGameIssue(game, "")
+				log.Printf("%s launched successfully", game.GameName)
+				go app.trackSession(proc)
+				return
+			}
+			// Only a protocol launch's opener can still be running here;
+			// don't leave it to hand the game over after all.
+			proc.stop()
+		}
+		log.Printf("Error launching %s: %v", game.GameName, err)
 	}
+	runHooks(game, hookPostSession, game.Hooks.PostSession)
+	app.launchFailed(game, err)
+}
 
+// launchFailed records a launch that was aborted or could not be started or
+// verified, and surfaces it in the tray, the manager window and a
+// notification.
+func (app *App) launchFailed(game Game, err error) {
+	// Suppress the rest of the window so the scheduler doesn't retry every minute.
 	app.recordLaunch(game)
-	log.Printf("%s launched successfully", game.GameName)
+	app.recordHistory(game, historyLaunchFailed, err.Error())
+	app.setLaunchFailure(fmt.Sprintf("%s failed to launch", game.GameName))
+	app.setGameIssue(game, "Failed to launch")
+	sendNativeNotification("Frictionless", fmt.Sprintf("%s failed to launch: %v", game.GameName, err))
 }
 
-// appLogDir returns the platform-appropriate directory for log files.
-func appLogDir() string {
-	switch {
-	case runtime.GOOS == "windows":
-		return filepath.Join(os.Getenv("LOCALAPPDATA"), "FrictionlessLauncher")
-	case fileExists("/Users"):
-		home, _ := os.UserHomeDir()
-		return filepath.Join(home, "Library", "Application Support", "FrictionlessLauncher")
-	default:
-		home, _ := os.UserHomeDir()
-		return filepath.Join(home, ".config", "FrictionlessLauncher")
+// rejectConfig reports a config file that could not be parsed and keeps the
+// last good config active, or the defaults if there is none yet.
+func (app *App) rejectConfig(errs []ConfigError) {
+	for _, e := range errs {
+		log.Printf("Config error: %v", e)
+	}
+	if app.currentConfig() == nil {
+		log.Println("Using default config until config.yaml is fixed")
+		app.setConfig(defaultConfig())
+	} else {
+		log.Println("Keeping the last good config until config.yaml is fixed")
+	}
+	app.setConfigErrors(errs, true)
+	if !app.readOnly {
+		sendNativeNotification("Frictionless", fmt.Sprintf("config.yaml could not be loaded: %v", errs[0]))
+	}
+}
+
+// setConfigErrors records the problems shown at the top of the manager
+// window and whether they stopped the file loading; nil clears them.
+func (app *App) setConfigErrors(errs []ConfigError, rejected bool) {
+	app.mu.Lock()
+	app.configErrors = errs
+	app.configRejected = rejected
+	app.mu.Unlock()
+	if app.ui != nil {
+		fyne.Do(app.ui.refresh)
+	}
+}
+
+// setLaunchFailure updates the failure shown at the top of the tray menu;
+// an empty message clears it.
+func (app *App) setLaunchFailure(msg string) {
+	app.mu.Lock()
+	changed := app.launchFailure != msg
+	app.launchFailure = msg
+	app.mu.Unlock()
+	if changed {
+		app.refreshTrayMenu()
 	}
 }
 
+// setGameIssue records a problem shown next to the game in the manager
+// window; an empty issue clears it.
+func (app *App) setGameIssue(game Game, issue string) {
+	app.mu.Lock()
+	if issue == "" {
+		delete(app.gameIssues, game.key())
+	} else {
+		if app.gameIssues == nil {
+			app.gameIssues = make(map[string]string)
+		}
+		app.gameIssues[game.key()] = issue
+	}
+	app.mu.Unlock()
+	if app.ui != nil {
+		fyne.Do(app.ui.refresh)
+	}
+}
+
+// openLogFile opens the launcher's own log file with the OS default program.
 func (app *App) openLogFile() {
 	openFileWithOS(filepath.Join(appLogDir(), "frictionless-launcher.log"))
 }
@@ -721,43 +938,6 @@ func fileExists(path string) bool {
 	return !os.IsNotExist(err)
 }
 
-func getConfigPath() string {
-	// Try local directory first (for development/portable installs)
-	exe, _ := os.Executable()
-	localDir := filepath.Dir(exe)
-	localConfig := filepath.Join(localDir, "config.yaml")
-
-	if _, err := os.Stat(localConfig); err == nil {
-		return localConfig
-	}
-
-	// Fall back to OS-appropriate location
-	var configDir string
-
-	switch {
-	case strings.Contains(strings.ToLower(os.Getenv("OS")), "windows"):
-		// Windows: %LOCALAPPDATA%\FrictionlessLauncher\config.json
-		configDir = filepath.Join(os.Getenv("LOCALAPPDATA"), "FrictionlessLauncher")
-	case fileExists("/Users"):
-		// macOS: ~/Library/Application Support/FrictionlessLauncher/config.json
-		home, _ := os.UserHomeDir()
-		configDir = filepath.Join(home, "Library", "Application Support", "FrictionlessLauncher")
-	default:
-		// Linux: ~/.config/FrictionlessLauncher/config.json
-		home, _ := os.UserHomeDir()
-		configDir = filepath.Join(home, ".config", "FrictionlessLauncher")
-	}
-
-	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0755); err != nil {
-		log.Printf("Warning: Could not create config directory %s: %v", configDir, err)
-		// Fall back to local directory
-		return localConfig
-	}
-
-	return filepath.Join(configDir, "config.yaml")
-}
-
 func (app *App) setupLogging() {
 	logDir := appLogDir()
 
@@ -829,15 +1009,15 @@ func cleanupOldLogs(logDir string) {
 }
 
 func (app *App) isPlatformRunning(platform string) bool {
-	var names []string
-	switch platform {
-	case "steam":
-		names = []string{"steam", "steam.exe", "Steam"}
-	case "epic":
-		names = []string{"EpicGamesLauncher", "EpicGamesLauncher.exe"}
-	default:
+	client, ok := platformClients[platform]
+	if !ok {
 		return false
 	}
+	names := client.Processes
+	var sandboxMarkers []string
+	if platform == "steam" {
+		sandboxMarkers = steamSandboxMarkers
+	}
 	procs, err := process.Processes()
 	if err != nil {
 		return false
@@ -852,30 +1032,58 @@ func (app *App) isPlatformRunning(platform string) bool {
 				return true
 			}
 		}
+		if len(sandboxMarkers) == 0 {
+			continue
+		}
+		// Flatpak and Snap clients can run under bwrap or a renamed
+		// launcher; recognise them by where they were started from.
+		if cmdline, err := p.Cmdline(); err == nil && isSandboxedProcess(cmdline, sandboxMarkers) {
+			return true
+		}
 	}
 	return false
 }
 
 func (app *App) isGameRunning() bool {
-	// For steam/epic, hasLaunchedInCurrentWindow prevents double-launches.
+	// Steam reports which of its games is running. For other protocol launches,
+	// hasLaunchedInCurrentWindow prevents double-launches.
 	// Only check processes for direct-launch games where we have a real executable path.
+	runningAppID, _ := steamRunningAppID()
 	var directGames []Game
-	for _, game := range app.config.Games {
-		if game.LaunchMethod == "direct" && game.GamePath != "" {
+	for _, game := range app.currentConfig().Games {
+		if isProcessTrackable(game) {
 			directGames = append(directGames, game)
 		}
+		if game.LaunchMethod != "steam" {
+			continue
+		}
+		if appID, ok := steamAppID(game.GamePath); ok && appID == runningAppID {
+			log.Printf("Steam reports %s is running", game.GameName)
+			return true
+		}
 	}
 	if len(directGames) == 0 {
 		return false
 	}
+	return gameProcessRunning(directGames...)
+}
 
+// isProcessTrackable reports whether a game's process can be found by
+// executable name, i.e. it was configured with a real executable path.
+func isProcessTrackable(game Game) bool {
+	return (game.LaunchMethod == "direct" || game.LaunchMethod == "wine") && game.GamePath != ""
+}
+
+// gameProcessRunning reports whether any of the given games' executables
+// appear in the process list.
+func gameProcessRunning(games ...Game) bool {
 	processes, err := process.Processes()
 	if err != nil {
 		log.Printf("Error checking processes: %v", err)
 		return false
 	}
 
-	for _, game := range directGames {
+	for _, game := range games {
 		exeName := filepath.Base(game.GamePath)
 		for _, proc := range processes {
 			name, err := proc.Name()
@@ -886,6 +1094,13 @@ func (app *App) isGameRunning() bool {
 				log.Printf("Game process found: %s", name)
 				return true
 			}
+			if game.LaunchMethod == "wine" {
+				if cmdline, err := proc.Cmdline(); err == nil && isWineProcessFor(name, cmdline, exeName) {
+					log.Printf("Game process found under wine: %s", cmdline)
+					return true
+				}
+				continue
+			}
 			exe, err := proc.Exe()
 			if err != nil {
 				continue
@@ -905,7 +1120,7 @@ func (app *App) hasLaunchedInCurrentWindow(game Game) bool {
 }
 
 func (app *App) hasLaunchedInCurrentWindowAt(game Game, now time.Time) bool {
-	lastLaunch, exists := app.lastLaunchTime[game.GameName]
+	lastLaunch, exists := app.lastLaunch(game)
 	if !exists {
 		return false
 	}
@@ -937,10 +1152,6 @@ func (app *App) hasLaunchedInCurrentWindowAt(game Game, now time.Time) bool {
 	return false
 }
 
-func (app *App) recordLaunch(game Game) {
-	app.lastLaunchTime[game.GameName] = time.Now()
-}
-
 func (app *App) closeLogFile() {
 	if app.logFile != nil {
 		log.Printf("=== Frictionless Launcher shutting down ===")
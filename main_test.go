@@ -552,7 +552,7 @@ func TestFileExists(t *testing.T) {
 	}
 }
 
-// ---- getConfigPath ----------------------------------------------------------
+// ---- locateConfig -----------------------------------------------------------
 
 func TestGetConfigPath_LocalConfig(t *testing.T) {
 	dir, _ := os.MkdirTemp("", "cfg_local_*")
@@ -581,27 +581,12 @@ func TestGetConfigPath_OSSpecific(t *testing.T) {
 	}
 }
 
-// getConfigPathWithExecutable replicates getConfigPath with a controllable
-// executable path so tests can verify fallback logic without touching real FS.
+// getConfigPathWithExecutable is the config locateConfig picks for a
+// controllable executable path, so tests can check the fallback without
+// touching the real binary's directory.
 func getConfigPathWithExecutable(executablePath string) string {
-	localDir := filepath.Dir(executablePath)
-	localConfig := filepath.Join(localDir, "config.yaml")
-	if _, err := os.Stat(localConfig); err == nil {
-		return localConfig
-	}
-
-	var configDir string
-	switch {
-	case strings.Contains(strings.ToLower(os.Getenv("OS")), "windows"):
-		configDir = filepath.Join(os.Getenv("LOCALAPPDATA"), "FrictionlessLauncher")
-	case fileExists("/Users"):
-		home, _ := os.UserHomeDir()
-		configDir = filepath.Join(home, "Library", "Application Support", "FrictionlessLauncher")
-	default:
-		home, _ := os.UserHomeDir()
-		configDir = filepath.Join(home, ".config", "FrictionlessLauncher")
-	}
-	return filepath.Join(configDir, "config.yaml")
+	path, _ := locateConfig("", executablePath)
+	return path
 }
 
 // ---- cleanupOldLogs ---------------------------------------------------------
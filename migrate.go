@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// configMigrations upgrade the config schema one version at a time:
+// configMigrations[i] turns a version i config into version i+1. Files
+// written before the version field existed are version 0. Each migration
+// returns a description of every change it made, for the log.
+var configMigrations = []struct {
+	name    string
+	migrate func(cfg *Config) []string
+}{
+	{"move the single legacy game into games", migrateLegacyGame},
+	{"normalize schedule day names", migrateNormalizeDays},
+	{"assign game IDs", assignGameIDs},
+}
+
+// currentConfigVersion is the schema version this build writes.
+var currentConfigVersion = len(configMigrations)
+
+// migrateConfig runs every migration newer than cfg.Version and stamps it
+// with the current version. It returns what changed, or nil when the config
+// was already current and needs no rewrite.
+func migrateConfig(cfg *Config) []string {
+	if cfg.Version > currentConfigVersion {
+		log.Printf("WARNING: config version %d is newer than this build's %d; unknown settings will be ignored",
+			cfg.Version, currentConfigVersion)
+		return nil
+	}
+	if cfg.Version == currentConfigVersion {
+		return nil
+	}
+	var changes []string
+	for v := cfg.Version; v < currentConfigVersion; v++ {
+		m := configMigrations[v]
+		for _, c := range m.migrate(cfg) {
+			changes = append(changes, fmt.Sprintf("v%d→v%d (%s): %s", v, v+1, m.name, c))
+		}
+	}
+	changes = append(changes, fmt.Sprintf("version %d → %d", cfg.Version, currentConfigVersion))
+	cfg.Version = currentConfigVersion
+	return changes
+}
+
+// backupConfigFile copies the config's current contents to a timestamped
+// file next to it before a migration rewrites it.
+func backupConfigFile(path string, data []byte, now time.Time) (string, error) {
+	backup := fmt.Sprintf("%s.%s.bak", path, now.Format("20060102-150405"))
+	if err := os.WriteFile(backup, data, 0644); err != nil {
+		return "", err
+	}
+	return backup, nil
+}
+
+// migrateLegacyGame converts the original single-game format — top-level
+// game_path, game_name, launch_args, enabled and schedule — into a games
+// entry.
+func migrateLegacyGame(cfg *Config) []string {
+	if cfg.GamePath == "" || len(cfg.Games) > 0 {
+		return nil
+	}
+	game := Game{
+		GameName:     cfg.GameName,
+		GamePath:     cfg.GamePath,
+		LaunchMethod: "direct",
+		LaunchArgs:   cfg.LaunchArgs,
+		Enabled:      cfg.Enabled,
+		Schedules:    []Schedule{},
+	}
+	changes := []string{fmt.Sprintf("moved legacy game %q into games", cfg.GameName)}
+	if cfg.Schedule != "" {
+		schedules, err := parseLegacySchedule(cfg.Schedule)
+		if err != nil {
+			changes = append(changes, fmt.Sprintf("dropped legacy schedule %q: %v", cfg.Schedule, err))
+		} else {
+			game.Schedules = schedules
+			changes = append(changes, fmt.Sprintf("converted legacy schedule %q", cfg.Schedule))
+		}
+	}
+	cfg.Games = []Game{game}
+	cfg.GamePath = ""
+	cfg.GameName = ""
+	cfg.LaunchArgs = ""
+	cfg.Enabled = false
+	cfg.Schedule = ""
+	return changes
+}
+
+// legacyTimeRangeRe matches the "HH:MM-HH:MM" part of a legacy schedule.
+var legacyTimeRangeRe = regexp.MustCompile(`^(\d{1,2}:\d{2})-(\d{1,2}:\d{2})$`)
+
+// parseLegacySchedule parses the legacy schedule string: "always", or an
+// optional day part ("daily", "weekdays", "weekends", or a comma-separated
+// list of days and day ranges like "Mon-Fri,Sun") followed by an optional
+// "HH:MM-HH:MM" window, e.g. "weekdays 19:00-21:00" or "Sat,Sun".
+func parseLegacySchedule(s string) ([]Schedule, error) {
+	schedule := Schedule{Days: append([]string{}, weekDays...), StartTime: "00:00", EndTime: "23:59"}
+	fields := strings.Fields(s)
+	if len(fields) == 0 || len(fields) > 2 {
+		return nil, fmt.Errorf("unrecognized format")
+	}
+	if m := legacyTimeRangeRe.FindStringSubmatch(fields[len(fields)-1]); m != nil {
+		schedule.StartTime, schedule.EndTime = padTime(m[1]), padTime(m[2])
+		if !scheduleTimeRe.MatchString(schedule.StartTime) || !scheduleTimeRe.MatchString(schedule.EndTime) {
+			return nil, fmt.Errorf("invalid time window %q", m[0])
+		}
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 1 {
+		days, err := parseLegacyDays(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		schedule.Days = days
+	} else if len(fields) > 1 {
+		return nil, fmt.Errorf("unrecognized format")
+	}
+	return []Schedule{schedule}, nil
+}
+
+// parseLegacyDays expands a legacy schedule's day part into day names.
+func parseLegacyDays(s string) ([]string, error) {
+	switch strings.ToLower(s) {
+	case "always", "daily", "everyday":
+		return append([]string{}, weekDays...), nil
+	case "weekdays":
+		return append([]string{}, weekDays[:5]...), nil
+	case "weekends":
+		return append([]string{}, weekDays[5:]...), nil
+	}
+	var days []string
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		start := dayIndex(from)
+		if start < 0 {
+			return nil, fmt.Errorf("unknown day %q", from)
+		}
+		end := start
+		if isRange {
+			if end = dayIndex(to); end < 0 {
+				return nil, fmt.Errorf("unknown day %q", to)
+			}
+		}
+		for i := start; ; i = (i + 1) % len(weekDays) {
+			days = append(days, weekDays[i])
+			if i == end {
+				break
+			}
+		}
+	}
+	return days, nil
+}
+
+// dayIndex returns the position in weekDays of a day's abbreviation or full
+// name, in any case, or -1.
+func dayIndex(name string) int {
+	name = strings.TrimSpace(name)
+	for i, d := range weekDays {
+		full := time.Weekday((i + 1) % 7).String()
+		if strings.EqualFold(name, d) || strings.EqualFold(name, full) {
+			return i
+		}
+	}
+	return -1
+}
+
+// padTime zero-pads the hour of an "H:MM" time.
+func padTime(t string) string {
+	if len(t) == 4 && t[1] == ':' {
+		return "0" + t
+	}
+	return t
+}
+
+// migrateNormalizeDays rewrites schedule days to their canonical "Mon"
+// form, so "monday" or "TUE" from hand-edited files compare equal to the
+// names the UI writes.
+func migrateNormalizeDays(cfg *Config) []string {
+	var changes []string
+	for gi := range cfg.Games {
+		game := &cfg.Games[gi]
+		for si := range game.Schedules {
+			days := game.Schedules[si].Days
+			for di, day := range days {
+				if i := dayIndex(day); i >= 0 && day != weekDays[i] {
+					days[di] = weekDays[i]
+					changes = append(changes, fmt.Sprintf("%s: day %q → %q", game.GameName, day, weekDays[i]))
+				}
+			}
+		}
+	}
+	return changes
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Config migrations
+// ============================================================================
+
+func TestMigrateConfig_StampsCurrentVersion(t *testing.T) {
+	cfg := &Config{Games: []Game{{GameName: "Celeste"}}}
+	changes := migrateConfig(cfg)
+	if cfg.Version != currentConfigVersion {
+		t.Errorf("Version = %d, want %d", cfg.Version, currentConfigVersion)
+	}
+	if len(changes) == 0 || !strings.HasPrefix(changes[len(changes)-1], "version 0 → ") {
+		t.Errorf("expected the version bump to be reported, got %q", changes)
+	}
+	if changes := migrateConfig(cfg); changes != nil {
+		t.Errorf("a current config needs no migration, got %q", changes)
+	}
+}
+
+func TestMigrateConfig_NewerVersionLeftAlone(t *testing.T) {
+	cfg := &Config{Version: currentConfigVersion + 1, Games: []Game{{Schedules: []Schedule{{Days: []string{"monday"}}}}}}
+	if changes := migrateConfig(cfg); changes != nil {
+		t.Errorf("a newer config must not be migrated, got %q", changes)
+	}
+	if cfg.Version != currentConfigVersion+1 || cfg.Games[0].Schedules[0].Days[0] != "monday" {
+		t.Errorf("a newer config must not be changed: %+v", cfg)
+	}
+}
+
+func TestMigrateConfig_RunsOnlyNewerMigrations(t *testing.T) {
+	// A version 1 file already had its legacy fields moved; stray ones
+	// must not be turned into a second game.
+	cfg := &Config{Version: 1, GamePath: "/usr/games/old", Games: []Game{{GameName: "Celeste"}}}
+	migrateConfig(cfg)
+	if len(cfg.Games) != 1 || cfg.GamePath != "/usr/games/old" {
+		t.Errorf("the v0→v1 migration should not have run: %+v", cfg)
+	}
+}
+
+// ---- v0 → v1: legacy single game ------------------------------------------
+
+func TestMigrateLegacyGame(t *testing.T) {
+	cfg := &Config{GamePath: "/usr/games/mygame", GameName: "Mine", LaunchArgs: "-w", Enabled: true, Schedule: "weekdays 9:00-17:30"}
+	changes := migrateLegacyGame(cfg)
+	if len(changes) != 2 {
+		t.Errorf("expected the move and schedule conversion to be reported, got %q", changes)
+	}
+	if len(cfg.Games) != 1 {
+		t.Fatalf("expected 1 game, got %d", len(cfg.Games))
+	}
+	g := cfg.Games[0]
+	if g.GameName != "Mine" || g.GamePath != "/usr/games/mygame" || g.LaunchArgs != "-w" || !g.Enabled || g.LaunchMethod != "direct" {
+		t.Errorf("unexpected game: %+v", g)
+	}
+	if len(g.Schedules) != 1 || strings.Join(g.Schedules[0].Days, ",") != "Mon,Tue,Wed,Thu,Fri" ||
+		g.Schedules[0].StartTime != "09:00" || g.Schedules[0].EndTime != "17:30" {
+		t.Errorf("unexpected schedules: %+v", g.Schedules)
+	}
+	if cfg.GamePath != "" || cfg.GameName != "" || cfg.Schedule != "" || cfg.Enabled {
+		t.Errorf("legacy fields should be cleared: %+v", cfg)
+	}
+	if migrateLegacyGame(&Config{Games: []Game{{GameName: "x"}}}) != nil {
+		t.Error("a multi-game config has nothing to migrate")
+	}
+}
+
+func TestMigrateLegacyGame_UnparseableScheduleDropped(t *testing.T) {
+	cfg := &Config{GamePath: "/usr/games/mygame", Schedule: "after dinner"}
+	changes := migrateLegacyGame(cfg)
+	if len(cfg.Games) != 1 || len(cfg.Games[0].Schedules) != 0 {
+		t.Fatalf("expected the game without schedules, got %+v", cfg.Games)
+	}
+	if len(changes) != 2 || !strings.Contains(changes[1], `dropped legacy schedule "after dinner"`) {
+		t.Errorf("expected the dropped schedule to be reported, got %q", changes)
+	}
+}
+
+func TestParseLegacySchedule(t *testing.T) {
+	tests := []struct {
+		in               string
+		days, start, end string
+	}{
+		{"always", "Mon,Tue,Wed,Thu,Fri,Sat,Sun", "00:00", "23:59"},
+		{"daily 19:00-21:00", "Mon,Tue,Wed,Thu,Fri,Sat,Sun", "19:00", "21:00"},
+		{"weekends", "Sat,Sun", "00:00", "23:59"},
+		{"18:00-22:00", "Mon,Tue,Wed,Thu,Fri,Sat,Sun", "18:00", "22:00"},
+		{"mon-wed,Friday 8:00-9:30", "Mon,Tue,Wed,Fri", "08:00", "09:30"},
+		{"Sat-Mon", "Sat,Sun,Mon", "00:00", "23:59"},
+	}
+	for _, tt := range tests {
+		got, err := parseLegacySchedule(tt.in)
+		if err != nil {
+			t.Errorf("%q: unexpected error %v", tt.in, err)
+			continue
+		}
+		s := got[0]
+		if strings.Join(s.Days, ",") != tt.days || s.StartTime != tt.start || s.EndTime != tt.end {
+			t.Errorf("%q = %+v, want %s %s-%s", tt.in, s, tt.days, tt.start, tt.end)
+		}
+	}
+	for _, bad := range []string{"", "sometimes", "Mon 25:00-26:00", "Mon Tue Wed"} {
+		if _, err := parseLegacySchedule(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+// ---- v1 → v2: day names ----------------------------------------------------
+
+func TestMigrateNormalizeDays(t *testing.T) {
+	cfg := &Config{Games: []Game{{
+		GameName:  "Celeste",
+		Schedules: []Schedule{{Days: []string{"monday", "TUE", "Wed", "Funday"}}},
+	}}}
+	changes := migrateNormalizeDays(cfg)
+	if got := strings.Join(cfg.Games[0].Schedules[0].Days, ","); got != "Mon,Tue,Wed,Funday" {
+		t.Errorf("days = %s, want unknown days left for validation to report", got)
+	}
+	if len(changes) != 2 || changes[0] != `Celeste: day "monday" → "Mon"` {
+		t.Errorf("unexpected changes %q", changes)
+	}
+}
+
+// ---- loadConfig -------------------------------------------------------------
+
+func TestLoadConfig_MigrationBacksUpAndRewrites(t *testing.T) {
+	app, _ := newTestApp(t)
+	old := "games:\n  - game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    schedules:\n      - days: [friday]\n        start_time: \"19:00\"\n        end_time: \"21:00\"\n"
+	os.WriteFile(app.configPath, []byte(old), 0644)
+	app.loadConfig()
+
+	backups, _ := filepath.Glob(app.configPath + ".*.bak")
+	if len(backups) != 1 {
+		t.Fatalf("expected one timestamped backup, got %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); string(data) != old {
+		t.Errorf("backup should hold the original file, got:\n%s", data)
+	}
+	data, _ := os.ReadFile(app.configPath)
+	if !strings.Contains(string(data), fmt.Sprintf("version: %d", currentConfigVersion)) || !strings.Contains(string(data), "Fri") || strings.Contains(string(data), "friday") {
+		t.Errorf("expected the migrated config to be written, got:\n%s", data)
+	}
+
+	// Loading the now-current file changes nothing.
+	app.loadConfig()
+	if again, _ := filepath.Glob(app.configPath + ".*.bak"); len(again) != 1 {
+		t.Errorf("a current config should not be backed up again, got %v", again)
+	}
+}
+
+func TestBackupConfigFile_Timestamped(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	backup, err := backupConfigFile(path, []byte("games: []\n"), time.Date(2026, 10, 18, 15, 4, 5, 0, time.Local))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if backup != path+".20261018-150405.bak" {
+		t.Errorf("backup = %q", backup)
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+)
+
+// platformClient describes a launcher that games are started through by
+// handing its URL handler a protocol link.
+type platformClient struct {
+	Name       string   // display name used in warnings
+	Processes  []string // client process names across Windows, macOS and Linux
+	MacApp     string   // application name for "open -a" on macOS
+	WindowsExe string   // client executable relative to Program Files on Windows
+	StartArgs  []string // arguments that start the client quietly in the tray
+}
+
+// platformClients maps each protocol launch method to its client.
+var platformClients = map[string]platformClient{
+	"steam": {
+		Name:       "Steam",
+		Processes:  []string{"steam", "steam.exe", "Steam"},
+		MacApp:     "Steam",
+		WindowsExe: `Steam\steam.exe`,
+		StartArgs:  []string{"-silent"},
+	},
+	"epic": {
+		Name:       "Epic Games Launcher",
+		Processes:  []string{"EpicGamesLauncher", "EpicGamesLauncher.exe"},
+		MacApp:     "Epic Games Launcher",
+		WindowsExe: `Epic Games\Launcher\Portal\Binaries\Win64\EpicGamesLauncher.exe`,
+		StartArgs:  []string{"-silent"},
+	},
+	"battlenet": {
+		Name:       "Battle.net",
+		Processes:  []string{"Battle.net.exe", "Battle.net"},
+		MacApp:     "Battle.net",
+		WindowsExe: `Battle.net\Battle.net.exe`,
+	},
+	"ea": {
+		Name:       "EA app",
+		Processes:  []string{"EADesktop.exe", "EADesktop", "EA app", "Origin.exe", "Origin"},
+		MacApp:     "EA app",
+		WindowsExe: `Electronic Arts\EA Desktop\EA Desktop\EADesktop.exe`,
+	},
+	"ubisoft": {
+		Name:       "Ubisoft Connect",
+		Processes:  []string{"UbisoftConnect.exe", "upc.exe", "Ubisoft Connect"},
+		WindowsExe: `Ubisoft\Ubisoft Game Launcher\UbisoftConnect.exe`,
+	},
+}
+
+// --- Battle.net ---
+
+// battlenetProducts maps the product codes Battle.net.config keys its
+// "Games" section by to the game's name and its battlenet:// launch code.
+// Battle.net has no local catalogue of names, so unknown codes are skipped.
+var battlenetProducts = map[string]struct{ Name, Code string }{
+	"wow":         {"World of Warcraft", "WoW"},
+	"wow_classic": {"World of Warcraft Classic", "WoWC"},
+	"prometheus":  {"Overwatch 2", "Pro"},
+	"fenris":      {"Diablo IV", "Fen"},
+	"d3":          {"Diablo III", "D3"},
+	"osi":         {"Diablo II: Resurrected", "OSI"},
+	"hs_beta":     {"Hearthstone", "WTCG"},
+	"heroes":      {"Heroes of the Storm", "Hero"},
+	"s2":          {"StarCraft II", "S2"},
+	"s1":          {"StarCraft Remastered", "S1"},
+	"w3":          {"Warcraft III: Reforged", "W3"},
+	"rtro":        {"Blizzard Arcade Collection", "RTRO"},
+}
+
+func battlenetConfigPath() string {
+	home, _ := os.UserHomeDir()
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Battle.net", "Battle.net.config")
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "Battle.net", "Battle.net.config")
+	default:
+		return ""
+	}
+}
+
+func discoverBattlenetGames() []DiscoveredGame {
+	path := battlenetConfigPath()
+	if path == "" {
+		return nil
+	}
+	return discoverBattlenetGamesFrom(path)
+}
+
+// discoverBattlenetGamesFrom lists the known products that have an entry in
+// the "Games" section of a Battle.net.config file.
+func discoverBattlenetGamesFrom(configPath string) []DiscoveredGame {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil
+	}
+	var cfg struct {
+		Games map[string]json.RawMessage `json:"Games"`
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil
+	}
+	keys := make([]string, 0, len(cfg.Games))
+	for k := range cfg.Games {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var games []DiscoveredGame
+	for _, k := range keys {
+		product, ok := battlenetProducts[k]
+		if !ok {
+			continue
+		}
+		games = append(games, DiscoveredGame{
+			Name:         product.Name,
+			LaunchMethod: "battlenet",
+			GamePath:     "battlenet://" + product.Code,
+		})
+	}
+	return games
+}
+
+// --- EA app ---
+//
+// The EA app keeps its install list in an encrypted file under ProgramData,
+// so EA games cannot be discovered and have to be added by hand with an
+// origin2://game/launch?offerIds=<id> link.
+
+// --- Ubisoft Connect ---
+//
+// Ubisoft Connect records installs in the Windows registry only; see
+// ubisoft_windows.go.
+
+// ubisoftLaunchURL returns the uplay:// link that starts the game with the
+// given Ubisoft Connect install ID.
+func ubisoftLaunchURL(id string) string {
+	return "uplay://launch/" + id + "/0"
+}
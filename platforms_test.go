@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Battle.net / EA app / Ubisoft Connect launch methods
+// ============================================================================
+
+func TestIsProtocolLaunch_PlatformClients(t *testing.T) {
+	for _, method := range []string{"steam", "epic", "battlenet", "ea", "ubisoft"} {
+		if !isProtocolLaunch(Game{LaunchMethod: method}) {
+			t.Errorf("%s should be a protocol launch", method)
+		}
+	}
+	for _, method := range []string{"direct", "wine", "flatpak", "desktop"} {
+		if isProtocolLaunch(Game{LaunchMethod: method}) {
+			t.Errorf("%s should not be a protocol launch", method)
+		}
+	}
+}
+
+func TestBuildLaunchCmd_PlatformClientsUseOpener(t *testing.T) {
+	for _, game := range []Game{
+		{LaunchMethod: "battlenet", GamePath: "battlenet://WoW"},
+		{LaunchMethod: "ea", GamePath: "origin2://game/launch?offerIds=OFB-EAST:109552153"},
+		{LaunchMethod: "ubisoft", GamePath: "uplay://launch/635/0"},
+	} {
+		cmd := buildLaunchCmd(game, "windows")
+		if got := strings.Join(cmd.Args, " "); got != "cmd /c start "+game.GamePath {
+			t.Errorf("%s on windows: got %q", game.LaunchMethod, got)
+		}
+		cmd = buildLaunchCmd(game, "darwin")
+		if got := strings.Join(cmd.Args, " "); got != "open -g "+game.GamePath {
+			t.Errorf("%s on darwin: got %q", game.LaunchMethod, got)
+		}
+	}
+}
+
+func TestPlatformClients_LaunchWarnings(t *testing.T) {
+	game := Game{
+		LaunchMethod: "battlenet",
+		GamePath:     "battlenet://WoW",
+		LaunchArgs:   "-d3d11",
+		WorkingDir:   "/tmp",
+		Wrappers:     []string{"gamemoderun"},
+	}
+	if w := launchArgsWarning(game); !strings.Contains(w, "Battle.net") {
+		t.Errorf("launchArgsWarning() = %q, want a Battle.net warning", w)
+	}
+	if w := launchEnvWarning(game); w == "" {
+		t.Error("battlenet launch should warn that working dir is not applied")
+	}
+	if w := launchWrappersNote(game); !strings.Contains(w, "Battle.net") {
+		t.Errorf("launchWrappersNote() = %q, want a Battle.net note", w)
+	}
+	if err := checkWrappers(Game{LaunchMethod: "ubisoft", Wrappers: []string{"no-such-wrapper"}}); err != nil {
+		t.Errorf("checkWrappers() should skip protocol launches, got %v", err)
+	}
+}
+
+func TestUbisoftLaunchURL(t *testing.T) {
+	if got := ubisoftLaunchURL("635"); got != "uplay://launch/635/0" {
+		t.Errorf("ubisoftLaunchURL() = %q", got)
+	}
+}
+
+// ============================================================================
+// discoverBattlenetGamesFrom — mocked Battle.net.config
+// ============================================================================
+
+func TestDiscoverBattlenetGamesFrom_KnownProducts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Battle.net.config")
+	config := `{
+  "Client": {"Install": {"DefaultInstallPath": "C:\\Program Files (x86)"}},
+  "Games": {
+    "battle_net": {"LastActioned": "1"},
+    "wow": {"Resumable": "false", "ServerUid": "wow_enus"},
+    "prometheus": {"LastPlayed": "1700000000"},
+    "someunknownbeta": {}
+  }
+}`
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	games := discoverBattlenetGamesFrom(path)
+	if len(games) != 2 {
+		t.Fatalf("expected 2 games, got %d: %+v", len(games), games)
+	}
+	if games[0].Name != "Overwatch 2" || games[0].GamePath != "battlenet://Pro" {
+		t.Errorf("unexpected first game: %+v", games[0])
+	}
+	if games[1].Name != "World of Warcraft" || games[1].GamePath != "battlenet://WoW" {
+		t.Errorf("unexpected second game: %+v", games[1])
+	}
+	for _, g := range games {
+		if g.LaunchMethod != "battlenet" {
+			t.Errorf("expected launch_method=battlenet, got %q", g.LaunchMethod)
+		}
+	}
+}
+
+func TestDiscoverBattlenetGamesFrom_BadFile(t *testing.T) {
+	if games := discoverBattlenetGamesFrom("/nonexistent/Battle.net.config"); games != nil {
+		t.Errorf("expected nil for missing file, got %v", games)
+	}
+	path := filepath.Join(t.TempDir(), "Battle.net.config")
+	os.WriteFile(path, []byte("not json"), 0644)
+	if games := discoverBattlenetGamesFrom(path); games != nil {
+		t.Errorf("expected nil for malformed file, got %v", games)
+	}
+}
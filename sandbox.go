@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Ways Steam can be packaged on Linux.
+const (
+	packagingNative  = ""
+	packagingFlatpak = "flatpak"
+	packagingSnap    = "snap"
+)
+
+const steamFlatpakID = "com.valvesoftware.Steam"
+
+// steamSandboxMarkers identify a Flatpak or Snap Steam process by its
+// command line when its process name is not plain "steam".
+var steamSandboxMarkers = []string{steamFlatpakID, "/snap/steam/"}
+
+// detectSteamPackaging reports how Steam is installed on this machine. A
+// native install wins when several are present, matching what xdg-open would
+// pick. It is a variable so tests can pin the result.
+var detectSteamPackaging = func() string {
+	if runtime.GOOS != "linux" {
+		return packagingNative
+	}
+	home, _ := os.UserHomeDir()
+	return steamPackagingFrom(home, exec.LookPath)
+}
+
+// steamPackagingFrom implements detectSteamPackaging against a home
+// directory and PATH lookup.
+func steamPackagingFrom(home string, lookPath func(string) (string, error)) string {
+	if _, err := lookPath("steam"); err == nil {
+		return packagingNative
+	}
+	if _, err := lookPath("flatpak"); err == nil && fileExists(filepath.Join(home, ".var", "app", steamFlatpakID)) {
+		return packagingFlatpak
+	}
+	if _, err := lookPath("snap"); err == nil && fileExists(filepath.Join(home, "snap", "steam")) {
+		return packagingSnap
+	}
+	return packagingNative
+}
+
+// isSandboxedProcess reports whether a command line belongs to a sandboxed
+// client, e.g. "bwrap ... com.valvesoftware.Steam" or "/snap/steam/...".
+func isSandboxedProcess(cmdline string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(cmdline, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// flatpakRunCmd builds "flatpak run" for the flatpak launch method, where
+// GamePath is the application ID. Env and working directory have to be
+// passed as flatpak options to reach inside the sandbox.
+func flatpakRunCmd(game Game) *exec.Cmd {
+	program := []string{"flatpak", "run"}
+	keys := make([]string, 0, len(game.Env))
+	for k := range game.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		program = append(program, "--env="+k+"="+game.Env[k])
+	}
+	if game.WorkingDir != "" {
+		program = append(program, "--cwd="+game.WorkingDir)
+	}
+	program = append(program, game.GamePath)
+	return execGameCmd(game, program)
+}
+
+// flatpakAppRunning reports whether a Flatpak application has a running
+// instance, according to "flatpak ps".
+func flatpakAppRunning(appID string) bool {
+	out, err := exec.Command("flatpak", "ps", "--columns=application").Output()
+	if err != nil {
+		return false
+	}
+	return flatpakPsContains(string(out), appID)
+}
+
+func flatpakPsContains(output, appID string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == appID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func pinSteamPackaging(t *testing.T, packaging string) {
+	t.Helper()
+	old := detectSteamPackaging
+	detectSteamPackaging = func() string { return packaging }
+	t.Cleanup(func() { detectSteamPackaging = old })
+}
+
+func fakeLookPath(found ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, f := range found {
+			if f == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestSteamPackagingFrom(t *testing.T) {
+	home := t.TempDir()
+	if got := steamPackagingFrom(home, fakeLookPath("flatpak", "snap")); got != packagingNative {
+		t.Errorf("no sandboxed install present: got %q, want native", got)
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".var", "app", steamFlatpakID), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := steamPackagingFrom(home, fakeLookPath("flatpak")); got != packagingFlatpak {
+		t.Errorf("got %q, want flatpak", got)
+	}
+	if got := steamPackagingFrom(home, fakeLookPath("steam", "flatpak")); got != packagingNative {
+		t.Errorf("native steam should win, got %q", got)
+	}
+
+	snapHome := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(snapHome, "snap", "steam"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := steamPackagingFrom(snapHome, fakeLookPath("snap")); got != packagingSnap {
+		t.Errorf("got %q, want snap", got)
+	}
+}
+
+func TestBuildLaunchCmd_Steam_LinuxFlatpak(t *testing.T) {
+	pinSteamPackaging(t, packagingFlatpak)
+	cmd := buildLaunchCmd(Game{GamePath: "steam://rungameid/1", LaunchMethod: "steam"}, "linux")
+	if got := strings.Join(cmd.Args, " "); got != "flatpak run com.valvesoftware.Steam steam://rungameid/1" {
+		t.Errorf("args = %q", got)
+	}
+}
+
+func TestBuildLaunchCmd_Steam_LinuxSnap(t *testing.T) {
+	pinSteamPackaging(t, packagingSnap)
+	cmd := buildLaunchCmd(Game{GamePath: "steam://rungameid/1", LaunchMethod: "steam"}, "linux")
+	if got := strings.Join(cmd.Args, " "); got != "snap run steam steam://rungameid/1" {
+		t.Errorf("args = %q", got)
+	}
+}
+
+func TestBuildLaunchCmd_Steam_LinuxNative(t *testing.T) {
+	pinSteamPackaging(t, packagingNative)
+	cmd := buildLaunchCmd(Game{GamePath: "steam://rungameid/1", LaunchMethod: "steam"}, "linux")
+	if cmd.Args[0] != "xdg-open" {
+		t.Errorf("expected xdg-open for native steam, got %v", cmd.Args)
+	}
+}
+
+func TestBuildLaunchCmd_Flatpak(t *testing.T) {
+	game := Game{
+		GamePath:     "net.veloren.airshipper",
+		LaunchMethod: "flatpak",
+		LaunchArgs:   `--profile "My Profile"`,
+		Env:          map[string]string{"B": "2", "A": "1"},
+		WorkingDir:   "/tmp",
+		Wrappers:     []string{"gamemoderun"},
+	}
+	cmd := buildLaunchCmd(game, "linux")
+	want := "gamemoderun|flatpak|run|--env=A=1|--env=B=2|--cwd=/tmp|net.veloren.airshipper|--profile|My Profile"
+	if got := strings.Join(cmd.Args, "|"); got != want {
+		t.Errorf("args = %q, want %q", got, want)
+	}
+	if cmd.Env != nil || cmd.Dir != "" {
+		t.Error("flatpak env and cwd should be passed as options, not to the flatpak binary itself")
+	}
+}
+
+func TestIsSandboxedProcess(t *testing.T) {
+	if !isSandboxedProcess("bwrap --args 42 -- steam com.valvesoftware.Steam", steamSandboxMarkers) {
+		t.Error("expected flatpak steam to be recognised")
+	}
+	if !isSandboxedProcess("/snap/steam/189/usr/lib/steam/steam", steamSandboxMarkers) {
+		t.Error("expected snap steam to be recognised")
+	}
+	if isSandboxedProcess("/usr/bin/firefox", steamSandboxMarkers) {
+		t.Error("unrelated process should not match")
+	}
+}
+
+func TestFlatpakPsContains(t *testing.T) {
+	out := "Application\norg.libretro.RetroArch\ncom.valvesoftware.Steam\n"
+	if !flatpakPsContains(out, "org.libretro.RetroArch") {
+		t.Error("expected running app to be found")
+	}
+	if flatpakPsContains(out, "org.libretro") {
+		t.Error("partial app IDs should not match")
+	}
+}
+
+func TestSessionProbe_Flatpak(t *testing.T) {
+	if sessionProbe(Game{LaunchMethod: "flatpak", GamePath: "org.example.Game"}) == nil {
+		t.Error("flatpak games should be verifiable via flatpak ps")
+	}
+}
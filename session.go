@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+var (
+	// sessionPollInterval is how often a protocol-launched game's process is
+	// checked while tracking its session.
+	sessionPollInterval = 5 * time.Second
+	// sessionStartGrace is how long to wait for a protocol-launched game's
+	// process to appear before giving up on tracking the session.
+	sessionStartGrace = 2 * time.Minute
+	// verifyPollInterval is how often verifyLaunch checks for the game.
+	verifyPollInterval = time.Second
+)
+
+// launchedProcess is a started launch command. A single goroutine reaps it;
+// exited is closed once that has happened and err holds the Wait result.
+type launchedProcess struct {
+	game    Game
+	cmd     *exec.Cmd
+	started time.Time
+	exited  chan struct{}
+	err     error
+}
+
+// startLaunch starts cmd and begins reaping it in the background.
+func startLaunch(game Game, cmd *exec.Cmd) (*launchedProcess, error) {
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	proc := &launchedProcess{game: game, cmd: cmd, started: time.Now(), exited: make(chan struct{})}
+	go func() {
+		proc.err = cmd.Wait()
+		close(proc.exited)
+	}()
+	return proc, nil
+}
+
+// stop kills the launch command if it is still running and waits until it
+// has been reaped.
+func (proc *launchedProcess) stop() {
+	select {
+	case <-proc.exited:
+		return
+	default:
+	}
+	if err := proc.cmd.Process.Kill(); err != nil {
+		log.Printf("Error stopping %s: %v", proc.game.GameName, err)
+	}
+	<-proc.exited
+}
+
+// verifyLaunch waits up to timeout for probe to report the game running.
+// A direct launch whose process exits with an error first fails at once,
+// and one whose process is still running at the deadline counts as started
+// even if probe never saw it, since it may exec something else entirely.
+// Launches that cannot be probed, or a zero timeout, are trusted as started.
+func verifyLaunch(proc *launchedProcess, probe func() bool, timeout time.Duration) error {
+	if probe == nil || timeout <= 0 {
+		return nil
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		running := true
+		select {
+		case <-proc.exited:
+			if proc.err != nil && !isProtocolLaunch(proc.game) {
+				return fmt.Errorf("exited during startup: %w", proc.err)
+			}
+			running = false
+		default:
+		}
+		if probe() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if running && !isProtocolLaunch(proc.game) {
+				return nil
+			}
+			return fmt.Errorf("game process did not appear within %s", timeout)
+		}
+		time.Sleep(verifyPollInterval)
+	}
+}
+
+// trackSession blocks until the game session has ended, then runs the
+// game's post-session hooks. Direct launches are tracked through the child
+// itself; for protocol launches the child is only the opener, so the game's
+// process is polled instead when it can be identified.
+func (app *App) trackSession(proc *launchedProcess) {
+	game := proc.game
+	<-proc.exited
+	if !isProtocolLaunch(game) {
+		app.recordExit(proc)
+	} else {
+		probe := sessionProbe(game)
+		if probe == nil {
+			if len(game.Hooks.PostSession) > 0 {
+				log.Printf("Cannot track %s session for %s — skipping post-session hooks", game.LaunchMethod, game.GameName)
+			}
+			return
+		}
+		if !waitForSessionEnd(probe) {
+			log.Printf("%s process never appeared — skipping post-session hooks", game.GameName)
+			return
+		}
+	}
+
+	log.Printf("%s session ended", game.GameName)
+	runHooks(game, hookPostSession, game.Hooks.PostSession)
+}
+
+// recordExit logs a direct launch's exit status to history, flagging a
+// failing exit within the crash window as a crash in the tray and manager.
+func (app *App) recordExit(proc *launchedProcess) {
+	game := proc.game
+	code, signal := exitStatus(proc.cmd.ProcessState)
+	ran := time.Since(proc.started).Round(time.Second)
+
+	entry := HistoryEntry{
+		Time:     time.Now(),
+		Game:     game.GameName,
+		GameID:   game.ID,
+		Event:    historyExited,
+		Detail:   fmt.Sprintf("ran %s", ran),
+		ExitCode: &code,
+		Signal:   signal,
+	}
+	failed := code != 0 || signal != ""
+	if !failed {
+		log.Printf("%s exited normally after %s", game.GameName, ran)
+		app.appendHistory(entry)
+		return
+	}
+
+	reason := fmt.Sprintf("exit %d", code)
+	if signal != "" {
+		reason = signal
+	}
+	crashWindow := time.Duration(app.currentConfig().CrashWindow) * time.Second
+	if ran >= crashWindow {
+		log.Printf("%s exited with %s after %s", game.GameName, reason, ran)
+		app.appendHistory(entry)
+		return
+	}
+
+	log.Printf("%s crashed with %s after %s — output is in %s", game.GameName, reason, ran, app.gameLogPath(game))
+	entry.Event = historyCrashed
+	app.appendHistory(entry)
+	app.setLaunchFailure(fmt.Sprintf("%s crashed (%s)", game.GameName, reason))
+	app.setGameIssue(game, fmt.Sprintf("Crashed (%s)", reason))
+	sendNativeNotification("Frictionless", fmt.Sprintf("%s crashed shortly after launch (%s)", game.GameName, reason))
+}
+
+// exitStatus returns how a reaped process ended: its exit code (-1 when it
+// was killed) and the name of the signal that killed it, if any.
+func exitStatus(state *os.ProcessState) (int, string) {
+	if state == nil {
+		return -1, ""
+	}
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return -1, ws.Signal().String()
+	}
+	return state.ExitCode(), ""
+}
+
+// sessionProbe returns a check for whether the game is still running, or nil
+// when its process cannot be identified.
+func sessionProbe(game Game) func() bool {
+	switch {
+	case isProcessTrackable(game):
+		return func() bool { return gameProcessRunning(game) }
+	case game.LaunchMethod == "flatpak":
+		return func() bool { return flatpakAppRunning(game.GamePath) }
+	case game.LaunchMethod == "steam":
+		return steamSessionProbe(game)
+	}
+	return nil
+}
+
+// isProtocolLaunch reports whether the game is started by handing a URL to a
+// platform client rather than by running its executable ourselves.
+func isProtocolLaunch(game Game) bool {
+	_, ok := platformClients[game.LaunchMethod]
+	return ok
+}
+
+// waitForSessionEnd polls running until it has reported true and then false
+// again. It returns false if the process never showed up within
+// sessionStartGrace.
+func waitForSessionEnd(running func() bool) bool {
+	deadline := time.Now().Add(sessionStartGrace)
+	for !running() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(sessionPollInterval)
+	}
+	for running() {
+		time.Sleep(sessionPollInterval)
+	}
+	return true
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shirou/gopsutil/v4/process"
+)
+
+func fastVerifyPolling(t *testing.T) {
+	t.Helper()
+	old := verifyPollInterval
+	verifyPollInterval = time.Millisecond
+	t.Cleanup(func() { verifyPollInterval = old })
+}
+
+// exitedProcess returns a launchedProcess that has already been reaped with err.
+func exitedProcess(game Game, err error) *launchedProcess {
+	proc := &launchedProcess{game: game, exited: make(chan struct{}), err: err}
+	close(proc.exited)
+	return proc
+}
+
+func runningProcess(game Game) *launchedProcess {
+	return &launchedProcess{game: game, exited: make(chan struct{})}
+}
+
+func TestVerifyLaunch_NoProbeTrustsStart(t *testing.T) {
+	proc := runningProcess(Game{LaunchMethod: "steam"})
+	if err := verifyLaunch(proc, nil, time.Second); err != nil {
+		t.Errorf("expected unprobeable launch to be trusted, got %v", err)
+	}
+}
+
+func TestVerifyLaunch_ZeroTimeoutDisables(t *testing.T) {
+	proc := runningProcess(Game{LaunchMethod: "direct"})
+	if err := verifyLaunch(proc, func() bool { return false }, 0); err != nil {
+		t.Errorf("expected verification to be disabled, got %v", err)
+	}
+}
+
+func TestVerifyLaunch_ProcessAppears(t *testing.T) {
+	fastVerifyPolling(t)
+	calls := 0
+	probe := func() bool { calls++; return calls >= 3 }
+	if err := verifyLaunch(runningProcess(Game{LaunchMethod: "steam"}), probe, time.Second); err != nil {
+		t.Errorf("expected success once the process appears, got %v", err)
+	}
+}
+
+func TestVerifyLaunch_Timeout(t *testing.T) {
+	fastVerifyPolling(t)
+	err := verifyLaunch(runningProcess(Game{LaunchMethod: "steam"}), func() bool { return false }, 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "did not appear") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+}
+
+func TestVerifyLaunch_RunningDirectChildTrusted(t *testing.T) {
+	fastVerifyPolling(t)
+	// The child may exec something the probe doesn't know by name.
+	err := verifyLaunch(runningProcess(Game{LaunchMethod: "direct"}), func() bool { return false }, 20*time.Millisecond)
+	if err != nil {
+		t.Errorf("expected a running direct child to count as started, got %v", err)
+	}
+}
+
+func TestVerifyLaunch_DirectCleanExitFallsBackToProbe(t *testing.T) {
+	fastVerifyPolling(t)
+	proc := exitedProcess(Game{LaunchMethod: "direct"}, nil)
+	err := verifyLaunch(proc, func() bool { return false }, 20*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "did not appear") {
+		t.Errorf("expected a launcher that handed off to be probed, got %v", err)
+	}
+}
+
+func TestVerifyLaunch_DirectExitFailsFast(t *testing.T) {
+	fastVerifyPolling(t)
+	proc := exitedProcess(Game{LaunchMethod: "direct"}, errors.New("exit status 1"))
+	start := time.Now()
+	err := verifyLaunch(proc, func() bool { return false }, time.Minute)
+	if err == nil || !strings.Contains(err.Error(), "exited during startup") {
+		t.Errorf("expected startup exit error, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("expected verification to fail without waiting for the timeout")
+	}
+}
+
+func TestVerifyLaunch_ProtocolOpenerExitIgnored(t *testing.T) {
+	fastVerifyPolling(t)
+	proc := exitedProcess(Game{LaunchMethod: "steam"}, errors.New("exit status 4"))
+	calls := 0
+	probe := func() bool { calls++; return calls >= 2 }
+	if err := verifyLaunch(proc, probe, time.Second); err != nil {
+		t.Errorf("opener exit status should not fail a protocol launch, got %v", err)
+	}
+}
+
+func TestStartLaunch_StartError(t *testing.T) {
+	if _, err := startLaunch(Game{}, exec.Command("/absolutely/does/not/exist/game")); err == nil {
+		t.Error("expected start error for missing executable")
+	}
+}
+
+func TestLaunchGameByStruct_FailureRecordedAfterRetries(t *testing.T) {
+	game := Game{GameName: "Broken", GamePath: "/absolutely/does/not/exist/game", LaunchMethod: "direct"}
+	app := appWithGames([]Game{game})
+	app.config.LaunchRetries = 2
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+
+	app.launchGameByStruct(game)
+
+	if !strings.Contains(app.launchFailure, "Broken failed to launch") {
+		t.Errorf("expected tray failure status, got %q", app.launchFailure)
+	}
+	entries, err := readHistory(app.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Event != historyLaunchFailed || entries[0].Game != "Broken" {
+		t.Errorf("expected one launch_failed entry, got %+v", entries)
+	}
+	if _, ok := app.lastLaunchTime["Broken"]; !ok {
+		t.Error("a failed launch should still suppress the rest of the window")
+	}
+}
+
+func TestLaunchGameByStruct_FailedAttemptsRetriedAndHooksUndone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	fastVerifyPolling(t)
+	dir := t.TempDir()
+	pids, undone := filepath.Join(dir, "pids"), filepath.Join(dir, "undone")
+	// The wrapper exits cleanly without the game ever showing up.
+	game := Game{
+		GameName:     "Unseen",
+		GamePath:     filepath.Join(dir, "unseen-game"),
+		LaunchMethod: "direct",
+		Wrappers:     []string{"sh -c 'echo $$ >> " + pids + "'"},
+		Hooks:        Hooks{PostSession: []Hook{{Command: "touch " + undone}}},
+	}
+	app := appWithGames([]Game{game})
+	app.config.LaunchRetries = 1
+	app.config.LaunchVerifyTimeout = 1
+	app.historyPath = filepath.Join(dir, historyFileName)
+
+	app.launchGameByStruct(game)
+
+	data, _ := os.ReadFile(pids)
+	started := strings.Fields(string(data))
+	if len(started) != 2 {
+		t.Fatalf("expected two attempts, got pids %q", started)
+	}
+	if !fileExists(undone) {
+		t.Error("post-session hooks should undo the pre-launch ones after a failed launch")
+	}
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyLaunchFailed {
+		t.Errorf("expected one launch_failed entry, got %+v", entries)
+	}
+}
+
+func TestLaunchGameByStruct_WrapperScriptExecsOtherBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "pid")
+	// game_path is a script that execs a binary with a different name.
+	script := filepath.Join(dir, "start.sh")
+	os.WriteFile(script, []byte("#!/bin/sh\necho $$ > "+pidFile+"\nexec sleep 30\n"), 0o755)
+	game := Game{GameName: "Wrapped", GamePath: script, LaunchMethod: "direct"}
+	app := appWithGames([]Game{game})
+	app.config.LaunchRetries = 1
+	app.config.LaunchVerifyTimeout = 1
+	app.historyPath = filepath.Join(dir, historyFileName)
+
+	app.launchGameByStruct(game)
+
+	data, _ := os.ReadFile(pidFile)
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	if pid == 0 {
+		t.Fatal("launch script never ran")
+	}
+	t.Cleanup(func() {
+		if p, err := os.FindProcess(pid); err == nil {
+			p.Kill()
+		}
+	})
+	if alive, _ := process.PidExists(int32(pid)); !alive {
+		t.Error("a running wrapped game should not be stopped and retried")
+	}
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyLaunched {
+		t.Errorf("expected one launched entry, got %+v", entries)
+	}
+}
+
+func TestLaunchGameByStruct_SuccessClearsFailure(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses /bin/sh")
+	}
+	game := Game{GameName: "Quick", GamePath: "/bin/sh", LaunchMethod: "direct", LaunchArgs: "-c 'sleep 1'"}
+	app := appWithGames([]Game{game})
+	app.historyPath = filepath.Join(t.TempDir(), historyFileName)
+	app.launchFailure = "Quick failed to launch"
+
+	app.launchGameByStruct(game)
+
+	if app.launchFailure != "" {
+		t.Errorf("expected failure status cleared, got %q", app.launchFailure)
+	}
+	entries, _ := readHistory(app.historyPath)
+	if len(entries) != 1 || entries[0].Event != historyLaunched {
+		t.Errorf("expected one launched entry, got %+v", entries)
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+// App state is shared by the schedule monitor, the config watcher, launch,
+// countdown and session goroutines and Fyne callbacks, so every mutable
+// field lives behind App.mu and is only touched through the methods here.
+//
+// The config is published as a snapshot: currentConfig returns the active
+// *Config, which is never modified afterwards, so callers may keep using it
+// without the lock. Changes are made to a copy by updateConfig, or a whole
+// new config is swapped in by setConfig.
+
+// currentConfig returns the active config snapshot.
+func (app *App) currentConfig() *Config {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.config
+}
+
+// setConfig makes cfg, with any setting overrides applied, the active
+// config. cfg must not be modified afterwards.
+func (app *App) setConfig(cfg *Config) {
+	app.setLoadedConfig(cfg, nil)
+}
+
+// includedFiles returns the files merged into the active config, or nil.
+func (app *App) includedFiles() *configIncludes {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.includes
+}
+
+// setLoadedConfig makes cfg, loaded from config.yaml merged with inc, the
+// active config, with any setting overrides applied.
+func (app *App) setLoadedConfig(cfg *Config, inc *configIncludes) {
+	active := app.withOverrides(cfg)
+	app.mu.Lock()
+	app.config, app.fileConfig, app.includes = active, cfg, inc
+	app.mu.Unlock()
+}
+
+// configToSave returns the active config as it should be written: with the
+// overridden settings at the values the config files gave them.
+func (app *App) configToSave() *Config {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.withoutOverrides(app.config, app.fileConfig)
+}
+
+// gameSourceFile returns the included file game comes from, or "" for a
+// game from config.yaml.
+func (app *App) gameSourceFile(game Game) string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.includes == nil {
+		return ""
+	}
+	return app.includes.GameFiles[game.ID]
+}
+
+// updateConfig applies change to a copy of the active config, makes the copy
+// active and saves it. Concurrent updates are applied one after another, so
+// none is lost.
+func (app *App) updateConfig(change func(cfg *Config)) {
+	app.mu.Lock()
+	cfg := app.config.clone()
+	change(cfg)
+	assignGameIDs(cfg) // for games just added or imported
+	app.config = cfg
+	app.mu.Unlock()
+	app.saveConfig()
+}
+
+// clone returns a copy of c whose games list can be changed without
+// affecting c. Games and the values inside them are replaced, not modified
+// in place, so they can be shared.
+func (c *Config) clone() *Config {
+	if c == nil {
+		return defaultConfig()
+	}
+	copied := *c
+	copied.Games = slices.Clone(c.Games)
+	return &copied
+}
+
+// updateGame applies change to the game with the given key in the active
+// config, if it's still there, and saves the result.
+func (app *App) updateGame(key string, change func(game *Game)) {
+	app.updateConfig(func(cfg *Config) {
+		if i := gameIndex(cfg.Games, key); i >= 0 {
+			change(&cfg.Games[i])
+		}
+	})
+}
+
+// removeGame deletes the game with the given key from the config and saves it.
+func (app *App) removeGame(key string) {
+	app.updateConfig(func(cfg *Config) {
+		if i := gameIndex(cfg.Games, key); i >= 0 {
+			cfg.Games = slices.Delete(cfg.Games, i, i+1)
+		}
+	})
+}
+
+// lastLaunch returns when game was last launched (or its launch suppressed).
+func (app *App) lastLaunch(game Game) (time.Time, bool) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	t, ok := app.lastLaunchTime[game.key()]
+	return t, ok
+}
+
+func (app *App) recordLaunch(game Game) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.lastLaunchTime == nil {
+		app.lastLaunchTime = make(map[string]time.Time)
+	}
+	app.lastLaunchTime[game.key()] = time.Now()
+}
+
+// pendingLaunch is an auto-launch counting down in the tray.
+type pendingLaunch struct {
+	GameName    string
+	SecondsLeft int
+	Cancel      func()
+}
+
+// pending returns the auto-launch counting down, if any.
+func (app *App) pending() (pendingLaunch, bool) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	if app.cancelLaunch == nil {
+		return pendingLaunch{}, false
+	}
+	return pendingLaunch{app.pendingGameName, app.pendingSecondsLeft, app.cancelLaunch}, true
+}
+
+// setPending starts showing an auto-launch countdown in the tray.
+func (app *App) setPending(p pendingLaunch) {
+	app.mu.Lock()
+	app.pendingGameName, app.pendingSecondsLeft, app.cancelLaunch = p.GameName, p.SecondsLeft, p.Cancel
+	app.mu.Unlock()
+	app.refreshTrayMenu()
+}
+
+// tickPending counts the pending launch down by a second and returns the
+// seconds left.
+func (app *App) tickPending() int {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	app.pendingSecondsLeft--
+	return app.pendingSecondsLeft
+}
+
+// clearPending removes the auto-launch countdown from the tray.
+func (app *App) clearPending() {
+	app.setPending(pendingLaunch{})
+}
+
+// launchFailureMessage returns the failure shown at the top of the tray menu.
+func (app *App) launchFailureMessage() string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.launchFailure
+}
+
+// gameIssue returns the problem shown next to game in the manager window.
+func (app *App) gameIssue(game Game) string {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.gameIssues[game.key()]
+}
+
+// configProblems returns the config errors shown in the manager window and
+// whether they stopped config.yaml loading.
+func (app *App) configProblems() ([]ConfigError, bool) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+	return app.configErrors, app.configRejected
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+)
+
+// ============================================================================
+// Shared App state
+// ============================================================================
+
+// TestAppState_ConcurrentAccess drives the paths the schedule monitor, the
+// config watcher, a countdown and UI callbacks take at the same time. Run
+// with -race to check the locking.
+func TestAppState_ConcurrentAccess(t *testing.T) {
+	app, _ := newTestApp(t)
+	write := func(bootDelay int) {
+		data := fmt.Sprintf("version: %d\nboot_delay: %d\ngames:\n  - game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    launch_method: direct\n    enabled: true\n",
+			currentConfigVersion, bootDelay)
+		os.WriteFile(app.configPath, []byte(data), 0644)
+	}
+	write(10)
+	app.loadConfig()
+
+	const rounds = 50
+	var wg sync.WaitGroup
+	run := func(f func(i int)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				f(i)
+			}
+		}()
+	}
+	run(func(i int) { // watcher reloads
+		write(i)
+		app.reloadChangedConfig()
+	})
+	run(func(i int) { // UI toggles and edits
+		app.updateGame(app.currentConfig().Games[0].key(), func(g *Game) { g.Enabled = i%2 == 0 })
+	})
+	run(func(int) { // schedule monitor
+		for _, game := range app.currentConfig().Games {
+			app.hasLaunchedInCurrentWindow(game)
+			app.recordLaunch(game)
+		}
+	})
+	run(func(i int) { // countdown ticking while the tray menu is rebuilt
+		if i%10 == 0 {
+			app.setPending(pendingLaunch{GameName: "Celeste", SecondsLeft: 10, Cancel: func() {}})
+		}
+		app.tickPending()
+		app.buildTrayMenu(nil)
+		if i%10 == 9 {
+			app.clearPending()
+		}
+	})
+	run(func(i int) { // launch results shown in the tray and manager window
+		game := Game{GameName: "Celeste"}
+		app.setLaunchFailure(fmt.Sprintf("failure %d", i))
+		app.setGameIssue(game, "Crashed")
+		gameStatusLabel(app, game)
+		app.configProblems()
+	})
+	wg.Wait()
+
+	if cfg := app.currentConfig(); cfg == nil || len(cfg.Games) != 1 {
+		t.Fatalf("expected a single-game config after the run, got %+v", cfg)
+	}
+	if _, ok := app.pending(); ok {
+		t.Error("the countdown should have been cleared")
+	}
+}
+
+func TestUpdateConfig_LeavesOldSnapshotAlone(t *testing.T) {
+	app, _ := newTestApp(t)
+	app.config = defaultConfig()
+	app.config.Games = []Game{{GameName: "Celeste", Enabled: true}}
+
+	before := app.currentConfig()
+	app.updateGame("Celeste", func(g *Game) { g.Enabled = false })
+
+	if !before.Games[0].Enabled {
+		t.Error("a snapshot already handed out must not change")
+	}
+	if app.currentConfig().Games[0].Enabled {
+		t.Error("the update should be in the new snapshot")
+	}
+	app.updateGame("Portal", func(g *Game) { t.Error("there is no game Portal to change") })
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// runningAppIDRe matches the RunningAppID value Steam keeps in registry.vdf.
+var runningAppIDRe = regexp.MustCompile(`(?i)"RunningAppID"\s+"(\d+)"`)
+
+// steamRunningAppID reports the app ID of the Steam game currently running
+// ("0" when none) and whether Steam's state could be read at all. It is a
+// variable so tests can stand in for Steam.
+var steamRunningAppID = readSteamRunningAppID
+
+// parseRunningAppID extracts RunningAppID from the contents of a Steam
+// registry.vdf file.
+func parseRunningAppID(data []byte) (string, bool) {
+	m := runningAppIDRe.FindSubmatch(data)
+	if m == nil {
+		return "", false
+	}
+	return string(m[1]), true
+}
+
+// steamRegistryFiles returns where Steam may keep registry.vdf outside
+// Windows, where it uses the real registry instead.
+func steamRegistryFiles(home, goos string) []string {
+	if goos == "darwin" {
+		return []string{filepath.Join(home, "Library", "Application Support", "Steam", "registry.vdf")}
+	}
+	return []string{
+		filepath.Join(home, ".steam", "registry.vdf"),
+		filepath.Join(home, ".var", "app", steamFlatpakID, ".steam", "registry.vdf"),
+		filepath.Join(home, "snap", "steam", "common", ".steam", "registry.vdf"),
+	}
+}
+
+// runningAppIDFromFiles reads RunningAppID from the first readable file.
+func runningAppIDFromFiles(paths []string) (string, bool) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if id, ok := parseRunningAppID(data); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// steamGameRunning reports whether the Steam game with appID is running
+// according to Steam itself, and whether that could be determined.
+func steamGameRunning(appID string) (running, known bool) {
+	id, ok := steamRunningAppID()
+	if !ok {
+		return false, false
+	}
+	return id == appID, true
+}
+
+// steamSessionProbe returns a check for whether a steam-method game is
+// running, or nil when it has no app ID or Steam's state can't be read.
+func steamSessionProbe(game Game) func() bool {
+	appID, ok := steamAppID(game.GamePath)
+	if !ok {
+		return nil
+	}
+	if _, known := steamGameRunning(appID); !known {
+		return nil
+	}
+	return func() bool {
+		running, _ := steamGameRunning(appID)
+		return running
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"runtime"
+)
+
+// readSteamRunningAppID reads RunningAppID from Steam's registry.vdf.
+func readSteamRunningAppID() (string, bool) {
+	home, _ := os.UserHomeDir()
+	return runningAppIDFromFiles(steamRegistryFiles(home, runtime.GOOS))
+}
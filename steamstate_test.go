@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ============================================================================
+// Steam RunningAppID
+// ============================================================================
+
+// pinSteamRunningAppID makes Steam report appID as running; known=false
+// simulates Steam's state being unreadable.
+func pinSteamRunningAppID(t *testing.T, appID string, known bool) {
+	t.Helper()
+	old := steamRunningAppID
+	steamRunningAppID = func() (string, bool) { return appID, known }
+	t.Cleanup(func() { steamRunningAppID = old })
+}
+
+const sampleRegistryVDF = `"Registry"
+{
+	"HKCU"
+	{
+		"Software"
+		{
+			"Valve"
+			{
+				"Steam"
+				{
+					"language"		"english"
+					"RunningAppID"		"413150"
+					"AutoLoginUser"		"player"
+				}
+			}
+		}
+	}
+}`
+
+func TestParseRunningAppID(t *testing.T) {
+	if id, ok := parseRunningAppID([]byte(sampleRegistryVDF)); !ok || id != "413150" {
+		t.Errorf("parseRunningAppID() = %q, %v; want 413150, true", id, ok)
+	}
+	// Older clients write the key in lower case.
+	if id, ok := parseRunningAppID([]byte(`"runningappid"		"0"`)); !ok || id != "0" {
+		t.Errorf("parseRunningAppID() = %q, %v; want 0, true", id, ok)
+	}
+	if _, ok := parseRunningAppID([]byte(`"Steam" { }`)); ok {
+		t.Error("expected no RunningAppID in an empty Steam section")
+	}
+}
+
+func TestRunningAppIDFromFiles_FirstReadable(t *testing.T) {
+	home := t.TempDir()
+	paths := steamRegistryFiles(home, "linux")
+	flatpak := paths[1]
+	os.MkdirAll(filepath.Dir(flatpak), 0755)
+	os.WriteFile(flatpak, []byte(sampleRegistryVDF), 0644)
+
+	if id, ok := runningAppIDFromFiles(paths); !ok || id != "413150" {
+		t.Errorf("runningAppIDFromFiles() = %q, %v; want the Flatpak Steam's 413150", id, ok)
+	}
+	if _, ok := runningAppIDFromFiles(steamRegistryFiles(t.TempDir(), "darwin")); ok {
+		t.Error("expected no state without a registry.vdf")
+	}
+}
+
+func TestIsGameRunning_SteamRunningAppID(t *testing.T) {
+	app := appWithGames([]Game{
+		{GameName: "Stardew Valley", LaunchMethod: "steam", GamePath: "steam://rungameid/413150"},
+	})
+	pinSteamRunningAppID(t, "413150", true)
+	if !app.isGameRunning() {
+		t.Error("expected the running Steam game to be detected")
+	}
+	pinSteamRunningAppID(t, "0", true)
+	if app.isGameRunning() {
+		t.Error("RunningAppID 0 means no Steam game is running")
+	}
+	pinSteamRunningAppID(t, "1091500", true)
+	if app.isGameRunning() {
+		t.Error("a different Steam game should not count as this one")
+	}
+}
+
+func TestSessionProbe_Steam(t *testing.T) {
+	game := Game{LaunchMethod: "steam", GamePath: "steam://rungameid/413150"}
+
+	pinSteamRunningAppID(t, "", false)
+	if sessionProbe(game) != nil {
+		t.Error("expected no probe when Steam's state can't be read")
+	}
+
+	pinSteamRunningAppID(t, "413150", true)
+	probe := sessionProbe(game)
+	if probe == nil || !probe() {
+		t.Fatal("expected a probe reporting the game running")
+	}
+	pinSteamRunningAppID(t, "0", true)
+	if probe() {
+		t.Error("expected the probe to report the game stopped")
+	}
+
+	if sessionProbe(Game{LaunchMethod: "steam", GamePath: "steam://open/games"}) != nil {
+		t.Error("expected no probe without an app ID")
+	}
+}
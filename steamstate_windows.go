@@ -0,0 +1,23 @@
+//go:build windows
+
+package main
+
+import (
+	"strconv"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// readSteamRunningAppID reads RunningAppID from HKCU\Software\Valve\Steam.
+func readSteamRunningAppID() (string, bool) {
+	k, err := registry.OpenKey(registry.CURRENT_USER, `Software\Valve\Steam`, registry.QUERY_VALUE)
+	if err != nil {
+		return "", false
+	}
+	defer k.Close()
+	id, _, err := k.GetIntegerValue("RunningAppID")
+	if err != nil {
+		return "", false
+	}
+	return strconv.FormatUint(id, 10), true
+}
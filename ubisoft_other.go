@@ -0,0 +1,6 @@
+//go:build !windows
+
+package main
+
+// Ubisoft Connect only records installs in the Windows registry.
+func discoverUbisoftGames() []DiscoveredGame { return nil }
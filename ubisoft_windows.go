@@ -0,0 +1,48 @@
+//go:build windows
+
+package main
+
+import (
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+// ubisoftInstallsKey is where Ubisoft Connect lists installed games, one
+// subkey per install ID with the game's InstallDir.
+const ubisoftInstallsKey = `SOFTWARE\WOW6432Node\Ubisoft\Launcher\Installs`
+
+func discoverUbisoftGames() []DiscoveredGame {
+	installs, err := registry.OpenKey(registry.LOCAL_MACHINE, ubisoftInstallsKey, registry.ENUMERATE_SUB_KEYS)
+	if err != nil {
+		return nil
+	}
+	defer installs.Close()
+	ids, err := installs.ReadSubKeyNames(-1)
+	if err != nil {
+		return nil
+	}
+	sort.Strings(ids)
+
+	var games []DiscoveredGame
+	for _, id := range ids {
+		k, err := registry.OpenKey(installs, id, registry.QUERY_VALUE)
+		if err != nil {
+			continue
+		}
+		dir, _, err := k.GetStringValue("InstallDir")
+		k.Close()
+		if err != nil || dir == "" {
+			continue
+		}
+		// The registry has no display name; the install folder is named
+		// after the game.
+		games = append(games, DiscoveredGame{
+			Name:         filepath.Base(filepath.Clean(dir)),
+			LaunchMethod: "ubisoft",
+			GamePath:     ubisoftLaunchURL(id),
+		})
+	}
+	return games
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"io"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -97,7 +98,7 @@ func (ui *GameManagerUI) show() {
 }
 
 func (ui *GameManagerUI) refresh() {
-	games := ui.appRef.config.Games
+	games := ui.appRef.currentConfig().Games
 
 	var gameList *widget.List
 	gameList = widget.NewList(
@@ -107,6 +108,7 @@ func (ui *GameManagerUI) refresh() {
 				nil, nil,
 				widget.NewCheck("", nil),
 				container.NewHBox(
+					widget.NewButtonWithIcon("", theme.SearchIcon(), nil),
 					widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), nil),
 					widget.NewButtonWithIcon("", theme.DeleteIcon(), nil),
 				),
@@ -128,32 +130,41 @@ func (ui *GameManagerUI) refresh() {
 			right := border.Objects[2].(*fyne.Container)
 
 			left.Objects[0].(*widget.Label).SetText(game.GameName)
-			left.Objects[1].(*widget.Label).SetText("— " + gameStatusLabel(ui.appRef, game))
+			status := "— " + gameStatusLabel(ui.appRef, game)
+			source := ui.appRef.gameSourceFile(game)
+			if source != "" {
+				status += " · " + filepath.Base(source)
+			}
+			left.Objects[1].(*widget.Label).SetText(status)
 
 			enabledCheck.Checked = game.Enabled
 			enabledCheck.Refresh()
 			enabledCheck.OnChanged = func(checked bool) {
-				ui.appRef.config.Games[id].Enabled = checked
-				ui.appRef.saveConfig()
+				ui.appRef.updateGame(game.key(), func(g *Game) { g.Enabled = checked })
 				fyne.Do(ui.refresh)
 			}
 
 			right.Objects[0].(*widget.Button).OnTapped = func() {
+				ui.showTestLaunch(game)
+			}
+			right.Objects[1].(*widget.Button).OnTapped = func() {
 				ui.showGameEditor(&game, false, func(updated Game) {
-					ui.appRef.config.Games[id] = updated
-					ui.appRef.saveConfig()
+					ui.appRef.updateGame(game.key(), func(g *Game) { *g = updated })
 				})
 			}
-			right.Objects[1].(*widget.Button).OnTapped = func() {
+			right.Objects[2].(*widget.Button).OnTapped = func() {
+				if source != "" {
+					// It would come back from the included file on the next load.
+					dialog.ShowInformation("Delete Game",
+						fmt.Sprintf("%s comes from %s, which the launcher doesn't change. Remove it there, or untick it to stop it launching on this computer.", game.GameName, source),
+						ui.window)
+					return
+				}
 				dialog.ShowConfirm("Delete Game",
 					fmt.Sprintf("Remove %s from auto-launch?", game.GameName),
 					func(ok bool) {
 						if ok {
-							ui.appRef.config.Games = append(
-								ui.appRef.config.Games[:id],
-								ui.appRef.config.Games[id+1:]...,
-							)
-							ui.appRef.saveConfig()
+							ui.appRef.removeGame(game.key())
 							fyne.Do(ui.refresh)
 						}
 					},
@@ -170,8 +181,7 @@ func (ui *GameManagerUI) refresh() {
 
 	addBtn := widget.NewButtonWithIcon("Add Game", theme.ContentAddIcon(), func() {
 		ui.showGamePicker(func(created Game) {
-			ui.appRef.config.Games = append(ui.appRef.config.Games, created)
-			ui.appRef.saveConfig()
+			ui.appRef.updateConfig(func(cfg *Config) { cfg.Games = append(cfg.Games, created) })
 		})
 	})
 
@@ -183,7 +193,7 @@ func (ui *GameManagerUI) refresh() {
 			defer f.Close()
 			data, err := yaml.Marshal(struct {
 				Games []Game `yaml:"games"`
-			}{Games: ui.appRef.config.Games})
+			}{Games: ui.appRef.currentConfig().Games})
 			if err != nil {
 				dialog.ShowError(err, ui.window)
 				return
@@ -207,38 +217,45 @@ func (ui *GameManagerUI) refresh() {
 				dialog.ShowError(err, ui.window)
 				return
 			}
-			var imported struct {
-				Games []Game `yaml:"games"`
-			}
-			if err := yaml.Unmarshal(data, &imported); err != nil {
-				dialog.ShowError(fmt.Errorf("invalid YAML: %w", err), ui.window)
-				return
-			}
-			if len(imported.Games) == 0 {
-				dialog.ShowError(fmt.Errorf("no games found in file"), ui.window)
+			imported, err := parseImportedGames(data, ui.appRef.currentConfig().Emulators)
+			if err != nil {
+				dialog.ShowError(err, ui.window)
 				return
 			}
-			dialog.ShowConfirm("Import Games",
-				fmt.Sprintf("Replace all %d current game(s) with %d imported game(s)?", len(ui.appRef.config.Games), len(imported.Games)),
-				func(ok bool) {
-					if !ok {
-						return
-					}
-					ui.appRef.config.Games = imported.Games
-					ui.appRef.saveConfig()
-					fyne.Do(ui.refresh)
-				},
-				ui.window,
-			)
+			ui.showImportPreview(planImport(ui.appRef.currentConfig().Games, imported))
 		}, ui.window)
 	})
 
+	restoreBtn := widget.NewButtonWithIcon("Restore Backup", theme.HistoryIcon(), ui.showRestoreBackup)
+
 	footer := container.NewVBox(
 		widget.NewSeparator(),
-		container.NewHBox(addBtn, exportBtn, importBtn),
+		container.NewHBox(addBtn, exportBtn, importBtn, restoreBtn),
 	)
 
-	ui.window.SetContent(container.NewBorder(nil, footer, nil, nil, gameList))
+	ui.window.SetContent(container.NewBorder(ui.configErrorsBanner(), footer, nil, nil, gameList))
+}
+
+// configErrorsBanner lists the problems found in config.yaml, or returns nil
+// when the file loaded cleanly.
+func (ui *GameManagerUI) configErrorsBanner() fyne.CanvasObject {
+	errs, rejected := ui.appRef.configProblems()
+	if len(errs) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(errs)+1)
+	if rejected {
+		lines = append(lines, "⚠️ config.yaml could not be loaded, so the last good config is still in use. Saving here will overwrite the file.")
+	} else {
+		lines = append(lines, "⚠️ config.yaml has problems:")
+	}
+	for _, e := range errs {
+		lines = append(lines, "• "+e.Error())
+	}
+	label := newWarningLabel()
+	label.SetText(strings.Join(lines, "\n"))
+	label.Show()
+	return container.NewVBox(label, widget.NewSeparator())
 }
 
 func (ui *GameManagerUI) showGamePicker(onSave func(Game)) {
@@ -323,18 +340,27 @@ func scheduleOverlaps(start1, end1, start2, end2 string) bool {
 }
 
 // findOverlappingGame returns the name of any existing game whose schedule overlaps
-// with the given days+times, excluding the game being edited (skipName).
-func (ui *GameManagerUI) findOverlappingGame(skipName string, days []string, startTime, endTime string) string {
-	for _, existing := range ui.appRef.config.Games {
-		if existing.GameName == skipName {
+// with the given days+times, excluding the game being edited (key skipKey).
+func (ui *GameManagerUI) findOverlappingGame(skipKey string, days []string, startTime, endTime string) string {
+	for _, existing := range ui.appRef.currentConfig().Games {
+		if existing.key() == skipKey {
 			continue
 		}
-		for _, es := range existing.Schedules {
-			for _, eDay := range es.Days {
-				for _, newDay := range days {
-					if strings.EqualFold(eDay, newDay) && scheduleOverlaps(startTime, endTime, es.StartTime, es.EndTime) {
-						return existing.GameName
-					}
+		if overlappingDay(existing, days, startTime, endTime) != "" {
+			return existing.GameName
+		}
+	}
+	return ""
+}
+
+// overlappingDay returns the first of days on which one of game's schedules
+// overlaps startTime–endTime, or "" if none does.
+func overlappingDay(game Game, days []string, startTime, endTime string) string {
+	for _, s := range game.Schedules {
+		for _, eDay := range s.Days {
+			for _, day := range days {
+				if strings.EqualFold(eDay, day) && scheduleOverlaps(startTime, endTime, s.StartTime, s.EndTime) {
+					return day
 				}
 			}
 		}
@@ -342,6 +368,104 @@ func (ui *GameManagerUI) findOverlappingGame(skipName string, days []string, sta
 	return ""
 }
 
+// showTestLaunch shows what launching game would do right now — the
+// scheduler's verdict and the resolved command — without starting it.
+func (ui *GameManagerUI) showTestLaunch(game Game) {
+	plan := ui.appRef.planLaunch(game, time.Now())
+	text := widget.NewLabelWithStyle(plan.String(), fyne.TextAlignLeading, fyne.TextStyle{Monospace: true})
+	text.Wrapping = fyne.TextWrapWord
+	scroll := container.NewVScroll(text)
+	scroll.SetMinSize(fyne.NewSize(480, 240))
+	d := dialog.NewCustom("Test Launch", "Close", scroll, ui.window)
+	d.Resize(clampDialogSize(ui.window, fyne.NewSize(560, 360)))
+	d.Show()
+}
+
+// showRestoreBackup offers the saved copies of config.yaml, newest first, and
+// makes the chosen one the active config.
+func (ui *GameManagerUI) showRestoreBackup() {
+	backups := configBackups(ui.appRef.configPath)
+	if len(backups) == 0 {
+		dialog.ShowInformation("Restore Backup", "There are no backups of config.yaml yet.", ui.window)
+		return
+	}
+	labels := make([]string, len(backups))
+	for i, b := range backups {
+		labels[i] = fmt.Sprintf("%s — %s", b.ModTime.Format("Jan 2 15:04:05"), filepath.Base(b.Path))
+	}
+	choice := widget.NewSelect(labels, nil)
+	choice.SetSelectedIndex(0)
+	content := container.NewVBox(
+		widget.NewLabel("Replace config.yaml with a backup? The current file is kept as the newest backup."),
+		choice,
+	)
+	dialog.ShowCustomConfirm("Restore Backup", "Restore", "Cancel", content, func(ok bool) {
+		if !ok {
+			return
+		}
+		if err := ui.appRef.restoreConfigBackup(backups[choice.SelectedIndex()].Path); err != nil {
+			dialog.ShowError(err, ui.window)
+			return
+		}
+		ui.refresh()
+	}, ui.window)
+}
+
+// showImportPreview lists what importing would add and change, with a choice
+// per game, and merges the chosen games into the config if their schedules
+// don't overlap any other game's.
+func (ui *GameManagerUI) showImportPreview(items []importItem) {
+	counts := make(map[importStatus]int)
+	rows := container.NewVBox()
+	for i := range items {
+		item := &items[i]
+		counts[item.Status]++
+
+		text := fmt.Sprintf("%s — %s", item.Game.GameName, item.Status)
+		if len(item.Changes) > 0 {
+			text += ": " + strings.Join(item.Changes, ", ")
+		}
+		label := widget.NewLabel(text)
+		label.Wrapping = fyne.TextWrapWord
+
+		actions := item.actions()
+		labels := make([]string, len(actions))
+		for ai, a := range actions {
+			labels[ai] = a.String()
+		}
+		choice := widget.NewSelect(labels, func(string) {})
+		choice.SetSelected(item.Action.String())
+		choice.OnChanged = func(string) { item.Action = actions[choice.SelectedIndex()] }
+
+		rows.Add(container.NewBorder(nil, nil, nil, choice, label))
+	}
+	summary := widget.NewLabel(fmt.Sprintf("%d added, %d changed, %d unchanged. Games are matched by ID, then by path.",
+		counts[importAdded], counts[importChanged], counts[importUnchanged]))
+	summary.Wrapping = fyne.TextWrapWord
+
+	scroll := container.NewVScroll(rows)
+	scroll.SetMinSize(fyne.NewSize(480, 280))
+	d := dialog.NewCustomConfirm("Import Games", "Import", "Cancel", container.NewBorder(summary, nil, nil, nil, scroll), func(ok bool) {
+		if !ok {
+			return
+		}
+		merged, touched := applyImport(ui.appRef.currentConfig().Games, items)
+		if len(touched) == 0 {
+			return
+		}
+		if overlaps := importOverlaps(merged, touched); len(overlaps) > 0 {
+			e := dialog.NewError(fmt.Errorf("schedules would overlap:\n%s", strings.Join(overlaps, "\n")), ui.window)
+			e.SetOnClosed(func() { ui.showImportPreview(items) })
+			e.Show()
+			return
+		}
+		ui.appRef.updateConfig(func(cfg *Config) { cfg.Games, _ = applyImport(cfg.Games, items) })
+		ui.refresh()
+	}, ui.window)
+	d.Resize(clampDialogSize(ui.window, fyne.NewSize(560, 420)))
+	d.Show()
+}
+
 // showGameEditor opens the game edit form. When methodLocked is true, the
 // Launch Method field is omitted entirely — the user already picked a
 // discovered game (and thus its launch method) in the picker dialog, so
@@ -363,6 +487,20 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 		}, ui.window)
 	})
 
+	wineBinaryEntry := widget.NewEntry()
+	wineBinaryEntry.SetText(game.WineBinary)
+	wineBinaryEntry.SetPlaceHolder("wine or /path/to/proton (default: wine)")
+
+	winePrefixEntry := widget.NewEntry()
+	winePrefixEntry.SetText(game.WinePrefix)
+	winePrefixEntry.SetPlaceHolder("prefix path, e.g. ~/.wine-mygame")
+
+	emulatorSelect := widget.NewSelect(emulatorNames(ui.appRef.currentConfig().Emulators), nil)
+	emulatorSelect.PlaceHolder = "emulator profile (see emulators: in config.yaml)"
+	if game.Emulator != "" {
+		emulatorSelect.SetSelected(game.Emulator)
+	}
+
 	// pathRow is a single-slot container; we swap its contents based on method
 	pathRow := container.NewStack(pathEntry)
 
@@ -379,21 +517,116 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 		case "epic":
 			pathEntry.SetPlaceHolder("com.epicgames.launcher://apps/APPID/launch")
 			pathRow.Objects = []fyne.CanvasObject{pathEntry}
+		case "battlenet":
+			pathEntry.SetPlaceHolder("battlenet://WoW")
+			pathRow.Objects = []fyne.CanvasObject{pathEntry}
+		case "ea":
+			pathEntry.SetPlaceHolder("origin2://game/launch?offerIds=OFFERID")
+			pathRow.Objects = []fyne.CanvasObject{pathEntry}
+		case "ubisoft":
+			pathEntry.SetPlaceHolder("uplay://launch/GAMEID/0")
+			pathRow.Objects = []fyne.CanvasObject{pathEntry}
 		case "direct":
 			pathEntry.SetPlaceHolder("/path/to/game.exe")
 			pathRow.Objects = []fyne.CanvasObject{container.NewBorder(nil, nil, nil, browseBtn, pathEntry)}
+		case "flatpak":
+			pathEntry.SetPlaceHolder("Flatpak app ID, e.g. net.veloren.airshipper")
+			pathRow.Objects = []fyne.CanvasObject{pathEntry}
+		case "desktop":
+			pathEntry.SetPlaceHolder("/usr/share/applications/game.desktop")
+			pathRow.Objects = []fyne.CanvasObject{container.NewBorder(nil, nil, nil, browseBtn, pathEntry)}
+		case "wine":
+			pathEntry.SetPlaceHolder("/path/to/Game.exe")
+			pathRow.Objects = []fyne.CanvasObject{container.NewVBox(
+				container.NewBorder(nil, nil, nil, browseBtn, pathEntry),
+				wineBinaryEntry,
+				winePrefixEntry,
+			)}
+		case "emulator":
+			pathEntry.SetPlaceHolder("/path/to/rom.sfc")
+			pathRow.Objects = []fyne.CanvasObject{container.NewVBox(
+				emulatorSelect,
+				container.NewBorder(nil, nil, nil, browseBtn, pathEntry),
+			)}
 		}
 		pathRow.Refresh()
 	}
 
-	methodSelect := widget.NewSelect([]string{"steam", "epic", "direct"}, updatePathRow)
-	methodSelect.SetSelected(initialMethod)
-	updatePathRow(initialMethod)
-
 	argsEntry := widget.NewEntry()
 	argsEntry.SetText(game.LaunchArgs)
 	argsEntry.SetPlaceHolder("optional launch arguments")
 
+	// argsWarning tells the user up front when the chosen method cannot
+	// forward launch args, rather than letting them silently do nothing.
+	argsWarning := newWarningLabel()
+
+	envEntry := widget.NewMultiLineEntry()
+	envEntry.SetText(formatEnvLines(game.Env))
+	envEntry.SetPlaceHolder("KEY=value, one per line")
+	envEntry.SetMinRowsVisible(2)
+
+	workDirEntry := widget.NewEntry()
+	workDirEntry.SetText(game.WorkingDir)
+	workDirEntry.SetPlaceHolder("optional, defaults to the launcher's directory")
+	workDirBrowseBtn := widget.NewButton("Browse...", func() {
+		dialog.ShowFolderOpen(func(u fyne.ListableURI, err error) {
+			if err != nil || u == nil {
+				return
+			}
+			workDirEntry.SetText(u.Path())
+		}, ui.window)
+	})
+
+	wrappersEntry := widget.NewMultiLineEntry()
+	wrappersEntry.SetText(strings.Join(game.Wrappers, "\n"))
+	wrappersEntry.SetPlaceHolder("one per line, e.g. gamemoderun or gamescope -f --")
+	wrappersEntry.SetMinRowsVisible(2)
+
+	envWarning := newWarningLabel()
+	wrappersNote := newWarningLabel()
+
+	setWarning := func(label *widget.Label, warning string) {
+		label.SetText(warning)
+		if warning == "" {
+			label.Hide()
+		} else {
+			label.Show()
+		}
+	}
+
+	var methodSelect *widget.Select
+	updateWarnings := func() {
+		if methodSelect == nil {
+			return
+		}
+		// Env parse errors are reported on save.
+		env, _ := parseEnvLines(envEntry.Text)
+		draft := Game{
+			GamePath:     pathEntry.Text,
+			LaunchMethod: methodSelect.Selected,
+			LaunchArgs:   argsEntry.Text,
+			Env:          env,
+			WorkingDir:   workDirEntry.Text,
+			Wrappers:     splitLines(wrappersEntry.Text),
+		}
+		setWarning(argsWarning, launchArgsWarning(draft))
+		setWarning(envWarning, launchEnvWarning(draft))
+		setWarning(wrappersNote, launchWrappersNote(ui.appRef.resolveLaunch(draft)))
+	}
+	argsEntry.OnChanged = func(string) { updateWarnings() }
+	pathEntry.OnChanged = func(string) { updateWarnings() }
+	envEntry.OnChanged = func(string) { updateWarnings() }
+	workDirEntry.OnChanged = func(string) { updateWarnings() }
+	wrappersEntry.OnChanged = func(string) { updateWarnings() }
+
+	methodSelect = widget.NewSelect(launchMethods, func(method string) {
+		updatePathRow(method)
+		updateWarnings()
+	})
+	methodSelect.SetSelected(initialMethod)
+	updatePathRow(initialMethod)
+	updateWarnings()
+
 	allDays := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
 
 	type scheduleRow struct {
@@ -481,7 +714,15 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 	if !methodLocked {
 		formItems = append(formItems, widget.NewFormItem("Launch Method", methodSelect))
 	}
-	formItems = append(formItems, widget.NewFormItem("Launch Args", argsEntry))
+	formItems = append(formItems,
+		widget.NewFormItem("Launch Args", container.NewVBox(argsEntry, argsWarning)),
+		widget.NewFormItem("Environment", envEntry),
+		widget.NewFormItem("Working Dir", container.NewVBox(
+			container.NewBorder(nil, nil, nil, workDirBrowseBtn, workDirEntry),
+			envWarning,
+		)),
+		widget.NewFormItem("Wrappers", container.NewVBox(wrappersEntry, wrappersNote)),
+	)
 
 	form := container.NewVBox(
 		widget.NewForm(formItems...),
@@ -504,6 +745,28 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 			dialog.ShowError(fmt.Errorf("game path is required"), ui.window)
 			return
 		}
+		if _, err := splitArgs(argsEntry.Text); err != nil {
+			dialog.ShowError(fmt.Errorf("launch args: %w", err), ui.window)
+			return
+		}
+		env, err := parseEnvLines(envEntry.Text)
+		if err != nil {
+			dialog.ShowError(fmt.Errorf("environment: %w", err), ui.window)
+			return
+		}
+		wrappers := splitLines(wrappersEntry.Text)
+		if _, err := wrapperArgv(wrappers); err != nil {
+			dialog.ShowError(err, ui.window)
+			return
+		}
+		emulator := ""
+		if methodSelect.Selected == "emulator" {
+			if emulatorSelect.Selected == "" {
+				dialog.ShowError(fmt.Errorf("choose an emulator profile"), ui.window)
+				return
+			}
+			emulator = emulatorSelect.Selected
+		}
 
 		var schedules []Schedule
 		for ri, row := range rows {
@@ -539,7 +802,7 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 		}
 
 		for _, s := range schedules {
-			if conflict := ui.findOverlappingGame(game.GameName, s.Days, s.StartTime, s.EndTime); conflict != "" {
+			if conflict := ui.findOverlappingGame(game.key(), s.Days, s.StartTime, s.EndTime); conflict != "" {
 				dialog.ShowError(fmt.Errorf("a time window overlaps with %s", conflict), ui.window)
 				return
 			}
@@ -547,12 +810,20 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 
 		d.Hide()
 		onSave(Game{
+			ID:           game.ID,
 			GameName:     nameEntry.Text,
 			GamePath:     pathEntry.Text,
 			LaunchMethod: methodSelect.Selected,
 			LaunchArgs:   argsEntry.Text,
 			Enabled:      enabledCheck.Checked,
 			Schedules:    schedules,
+			Env:          env,
+			WorkingDir:   workDirEntry.Text,
+			Wrappers:     wrappers,
+			WineBinary:   wineFieldValue(methodSelect.Selected, wineBinaryEntry.Text),
+			WinePrefix:   wineFieldValue(methodSelect.Selected, winePrefixEntry.Text),
+			Emulator:     emulator,
+			Hooks:        game.Hooks,
 		})
 		fyne.Do(ui.refresh)
 	}
@@ -573,12 +844,16 @@ func (ui *GameManagerUI) showGameEditor(game *Game, methodLocked bool, onSave fu
 }
 
 // gameStatusLabel returns the short status shown next to a game's name in
-// the list: "Disabled", "No schedule", or its next upcoming launch time.
+// the list: a recent launch failure or crash, "Disabled", "No schedule", or
+// its next upcoming launch time.
 func gameStatusLabel(app *App, game Game) string {
 	return gameStatusLabelAt(app, game, time.Now())
 }
 
 func gameStatusLabelAt(app *App, game Game, now time.Time) string {
+	if issue := app.gameIssue(game); issue != "" {
+		return "⚠️ " + issue
+	}
 	if !game.Enabled {
 		return "Disabled"
 	}
@@ -587,3 +862,33 @@ func gameStatusLabelAt(app *App, game Game, now time.Time) string {
 	}
 	return app.nextScheduleLabelAt(game, now)
 }
+
+// wineFieldValue keeps wine-only editor fields out of the saved game when
+// another launch method is selected.
+func wineFieldValue(method, value string) string {
+	if method != "wine" {
+		return ""
+	}
+	return strings.TrimSpace(value)
+}
+
+// newWarningLabel returns a hidden, word-wrapped warning label for the game
+// editor; setWarning shows it once it has something to say.
+func newWarningLabel() *widget.Label {
+	label := widget.NewLabel("")
+	label.Wrapping = fyne.TextWrapWord
+	label.Importance = widget.WarningImportance
+	label.Hide()
+	return label
+}
+
+// splitLines returns the non-blank, trimmed lines of a multi-line entry.
+func splitLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
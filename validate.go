@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigError is a problem found in the config file, located by its YAML
+// line and column where known.
+type ConfigError struct {
+	File   string // set for problems in a file config.yaml includes
+	Line   int
+	Column int
+	Path   string // e.g. "games[1].schedules[0].start_time"
+	Msg    string
+}
+
+func (e ConfigError) Error() string {
+	var b strings.Builder
+	if e.File != "" {
+		b.WriteString(filepath.Base(e.File) + ": ")
+	}
+	if e.Line > 0 {
+		fmt.Fprintf(&b, "line %d", e.Line)
+		if e.Column > 0 {
+			fmt.Fprintf(&b, ", column %d", e.Column)
+		}
+		b.WriteString(": ")
+	}
+	if e.Path != "" {
+		b.WriteString(e.Path + ": ")
+	}
+	b.WriteString(e.Msg)
+	return b.String()
+}
+
+// weekDays are the day names schedules accept (matched case-insensitively).
+var weekDays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// scheduleTimeRe matches a zero-padded 24-hour time. Schedule windows are
+// compared as strings, so "9:00" would sort after "19:00".
+var scheduleTimeRe = regexp.MustCompile(`^([01]\d|2[0-3]):[0-5]\d$`)
+
+// yamlErrLineRe pulls the line number out of yaml.v3 error messages.
+var yamlErrLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// parseConfig decodes config YAML over cfg, which holds the defaults,
+// upgrades it to the current schema and validates the result. changes lists
+// what the migrations did. ok is false for syntax and type errors, after
+// which cfg must be discarded; otherwise errs lists problems with the
+// contents of an otherwise usable config.
+func parseConfig(data []byte, cfg *Config) (changes []string, errs []ConfigError, ok bool) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, yamlErrors(err), false
+	}
+	if len(root.Content) == 0 {
+		return nil, nil, true // empty file
+	}
+	cfg.Version = 0 // files without a version predate it
+	if err := root.Decode(cfg); err != nil {
+		return nil, yamlErrors(err), false
+	}
+	changes = migrateConfig(cfg)
+	return changes, validateConfig(root.Content[0], cfg, nil), true
+}
+
+// yamlErrors converts a yaml.v3 syntax or type error into ConfigErrors.
+func yamlErrors(err error) []ConfigError {
+	msgs := []string{err.Error()}
+	if te, ok := err.(*yaml.TypeError); ok {
+		msgs = te.Errors
+	}
+	errs := make([]ConfigError, 0, len(msgs))
+	for _, msg := range msgs {
+		m := yamlErrLineRe.FindStringSubmatch(msg)
+		if m == nil {
+			errs = append(errs, ConfigError{Msg: strings.TrimPrefix(msg, "yaml: ")})
+			continue
+		}
+		line, _ := strconv.Atoi(m[1])
+		errs = append(errs, ConfigError{Line: line, Msg: m[2]})
+	}
+	return errs
+}
+
+// validateConfig checks the decoded config's contents, using doc (the
+// document's top-level mapping) to locate each problem in the file. files
+// names the included file each node of a merged doc came from.
+func validateConfig(doc *yaml.Node, cfg *Config, files map[*yaml.Node]string) []ConfigError {
+	var errs []ConfigError
+	add := func(node *yaml.Node, path, msg string) {
+		e := ConfigError{Path: path, Msg: msg}
+		if node != nil {
+			e.File, e.Line, e.Column = files[node], node.Line, node.Column
+		}
+		errs = append(errs, e)
+	}
+	// at locates key within n, or n itself when the key is missing.
+	at := func(n *yaml.Node, key string) *yaml.Node {
+		if v := mappingValue(n, key); v != nil {
+			return v
+		}
+		return n
+	}
+
+	gamesNode := mappingValue(doc, "games")
+	for i, game := range cfg.Games {
+		path := fmt.Sprintf("games[%d]", i)
+		node := sequenceItem(gamesNode, i)
+
+		if strings.TrimSpace(game.GameName) == "" {
+			add(at(node, "game_name"), path+".game_name", "is required")
+		}
+		if strings.TrimSpace(game.GamePath) == "" {
+			add(at(node, "game_path"), path+".game_path", "is required")
+		}
+		if game.LaunchMethod != "" && !slices.Contains(launchMethods, game.LaunchMethod) {
+			add(at(node, "launch_method"), path+".launch_method",
+				fmt.Sprintf("unknown launch method %q (expected one of %s)", game.LaunchMethod, strings.Join(launchMethods, ", ")))
+		}
+		if game.LaunchMethod == "emulator" {
+			if _, ok := cfg.Emulators[game.Emulator]; !ok {
+				add(at(node, "emulator"), path+".emulator", fmt.Sprintf("no emulator profile named %q", game.Emulator))
+			}
+		}
+
+		schedulesNode := mappingValue(node, "schedules")
+		for si, s := range game.Schedules {
+			spath := fmt.Sprintf("%s.schedules[%d]", path, si)
+			snode := sequenceItem(schedulesNode, si)
+			if len(s.Days) == 0 {
+				add(at(snode, "days"), spath+".days", "needs at least one day")
+			}
+			daysNode := mappingValue(snode, "days")
+			for di, day := range s.Days {
+				if !slices.ContainsFunc(weekDays, func(d string) bool { return strings.EqualFold(d, day) }) {
+					add(sequenceItem(daysNode, di), fmt.Sprintf("%s.days[%d]", spath, di),
+						fmt.Sprintf("unknown day %q (expected %s)", day, strings.Join(weekDays, ", ")))
+				}
+			}
+			for _, f := range []struct{ key, value string }{{"start_time", s.StartTime}, {"end_time", s.EndTime}} {
+				if !scheduleTimeRe.MatchString(f.value) {
+					add(at(snode, f.key), spath+"."+f.key, fmt.Sprintf("%q is not a 24-hour HH:MM time", f.value))
+				}
+			}
+		}
+	}
+
+	includeNode := mappingValue(doc, "include")
+	for i, pattern := range cfg.Include {
+		if hasDirWildcard(pattern) {
+			add(sequenceItem(includeNode, i), fmt.Sprintf("include[%d]", i),
+				fmt.Sprintf("%q has a wildcard in a directory name; only the file name may have one, so it is skipped", pattern))
+		}
+	}
+
+	unknownYAMLKeys(doc, reflect.TypeFor[Config](), "", func(key *yaml.Node, path string) {
+		add(key, path, "unknown setting; it is ignored, and kept as is when saving")
+	})
+	return errs
+}
+
+// unknownYAMLKeys calls found for each key under n, which decodes into t,
+// that t doesn't define, with its path from the top of the file.
+func unknownYAMLKeys(n *yaml.Node, t reflect.Type, path string, found func(key *yaml.Node, path string)) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		fields := yamlFields(t)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			if _, known := fields[key.Value]; fields != nil && !known {
+				found(key, keyPath)
+				continue
+			}
+			if vt := yamlValueType(t, fields, key.Value); vt != nil {
+				unknownYAMLKeys(n.Content[i+1], vt, keyPath, found)
+			}
+		}
+	case yaml.SequenceNode:
+		if vt := yamlValueType(t, nil, ""); vt != nil {
+			for i, item := range n.Content {
+				unknownYAMLKeys(item, vt, fmt.Sprintf("%s[%d]", path, i), found)
+			}
+		}
+	}
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sequenceItem returns the i'th item of a sequence node, or nil.
+func sequenceItem(node *yaml.Node, i int) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return nil
+	}
+	return node.Content[i]
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Config validation
+// ============================================================================
+
+func TestParseConfig_ValidConfigHasNoErrors(t *testing.T) {
+	data := `
+boot_delay: 5
+games:
+  - game_name: "Stardew Valley"
+    game_path: "steam://rungameid/413150"
+    launch_method: "steam"
+    schedules:
+      - days: [thu, Fri]
+        start_time: "19:00"
+        end_time: "21:00"
+    enabled: true
+`
+	cfg := defaultConfig()
+	_, errs, ok := parseConfig([]byte(data), cfg)
+	if !ok || len(errs) != 0 {
+		t.Fatalf("expected a clean parse, got ok=%v errs=%v", ok, errs)
+	}
+	if cfg.BootDelay != 5 || len(cfg.Games) != 1 || cfg.LaunchVerifyTimeout != 60 {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+}
+
+func TestParseConfig_ContentErrorsHavePositions(t *testing.T) {
+	data := `games:
+  - game_name: "Celeste"
+    game_path: ""
+    launch_method: "stem"
+    schedules:
+      - days: [Mon, Funday]
+        start_time: "9:00"
+        end_time: "25:00"
+`
+	_, errs, ok := parseConfig([]byte(data), defaultConfig())
+	if !ok {
+		t.Fatalf("content errors should not stop the config loading: %v", errs)
+	}
+	want := []string{
+		`line 3, column 16: games[0].game_path: is required`,
+		`line 4, column 20: games[0].launch_method: unknown launch method "stem"`,
+		`line 6, column 21: games[0].schedules[0].days[1]: unknown day "Funday"`,
+		`line 7, column 21: games[0].schedules[0].start_time: "9:00" is not a 24-hour HH:MM time`,
+		`line 8, column 19: games[0].schedules[0].end_time: "25:00" is not a 24-hour HH:MM time`,
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %d: %v", len(want), len(errs), errs)
+	}
+	for i, w := range want {
+		if !strings.HasPrefix(errs[i].Error(), w) {
+			t.Errorf("error %d = %q, want prefix %q", i, errs[i].Error(), w)
+		}
+	}
+}
+
+func TestParseConfig_MissingKeyReportedAtGame(t *testing.T) {
+	data := `games:
+  - game_path: /usr/games/foo
+    launch_method: emulator
+    emulator: snes
+`
+	_, errs, _ := parseConfig([]byte(data), defaultConfig())
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if errs[0].Path != "games[0].game_name" || errs[0].Line != 2 {
+		t.Errorf("missing game_name should point at the game, got %+v", errs[0])
+	}
+	if errs[1].Path != "games[0].emulator" || !strings.Contains(errs[1].Msg, `"snes"`) {
+		t.Errorf("expected an unknown emulator profile error, got %+v", errs[1])
+	}
+}
+
+func TestParseConfig_ReportsUnknownKeys(t *testing.T) {
+	data := `future_setting: 1
+emulators:
+  snes: {binary: snes9x, core: x}
+games:
+  - game_name: Celeste
+    game_path: /opt/celeste/Celeste
+    lauch_args: -fullscreen
+    env: {ANY_NAME: "1"}
+    hooks:
+      pre_launch:
+        - command: true
+          timeout_s: 5
+`
+	_, errs, ok := parseConfig([]byte(data), defaultConfig())
+	if !ok {
+		t.Fatal("unknown keys shouldn't stop the config loading")
+	}
+	var got []string
+	for _, e := range errs {
+		got = append(got, fmt.Sprintf("%d:%s", e.Line, e.Path))
+	}
+	want := []string{"1:future_setting", "3:emulators.snes.core", "7:games[0].lauch_args", "12:games[0].hooks.pre_launch[0].timeout_s"}
+	if !slices.Equal(got, want) {
+		t.Errorf("unknown keys = %q, want %q", got, want)
+	}
+}
+
+func TestParseConfig_SyntaxAndTypeErrorsAreFatal(t *testing.T) {
+	_, errs, ok := parseConfig([]byte("games:\n  - game_name: [unclosed\n"), defaultConfig())
+	if ok || len(errs) == 0 || errs[0].Line == 0 {
+		t.Errorf("expected a located syntax error, got ok=%v %v", ok, errs)
+	}
+	_, errs, ok = parseConfig([]byte("boot_delay: soon\n"), defaultConfig())
+	if ok || len(errs) != 1 || errs[0].Line != 1 || !strings.Contains(errs[0].Msg, "cannot unmarshal") {
+		t.Errorf("expected a located type error, got ok=%v %v", ok, errs)
+	}
+}
+
+func TestLoadConfig_KeepsLastGoodConfigOnSyntaxError(t *testing.T) {
+	app, _ := newTestApp(t)
+	good := "games:\n  - game_name: Celeste\n    game_path: /opt/celeste/Celeste\n    launch_method: direct\n"
+	os.WriteFile(app.configPath, []byte(good), 0644)
+	app.loadConfig()
+	if len(app.config.Games) != 1 || app.configErrors != nil {
+		t.Fatalf("expected the good config to load cleanly, got %d games, errors %v", len(app.config.Games), app.configErrors)
+	}
+
+	os.WriteFile(app.configPath, []byte("games:\n  - game_name: [unclosed\n"), 0644)
+	app.loadConfig()
+
+	if len(app.config.Games) != 1 || app.config.Games[0].GameName != "Celeste" {
+		t.Errorf("expected the last good config to stay active, got %+v", app.config.Games)
+	}
+	if !app.configRejected || len(app.configErrors) == 0 {
+		t.Errorf("expected the rejection to be recorded, got %v %v", app.configRejected, app.configErrors)
+	}
+
+	os.WriteFile(app.configPath, []byte(good), 0644)
+	app.loadConfig()
+	if app.configRejected || app.configErrors != nil {
+		t.Error("expected errors cleared once the file is fixed")
+	}
+}
+
+func TestConfigErrorsBanner(t *testing.T) {
+	ui := newTestUI(t, nil)
+	if ui.configErrorsBanner() != nil {
+		t.Error("expected no banner without errors")
+	}
+	ui.appRef.configErrors = []ConfigError{{Line: 4, Column: 20, Path: "games[0].launch_method", Msg: `unknown launch method "stem"`}}
+	banner := ui.configErrorsBanner()
+	if banner == nil || !containsLabelText(banner, `line 4, column 20: games[0].launch_method`) {
+		t.Error("expected the banner to list the located error")
+	}
+}
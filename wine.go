@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// isProtonBinary reports whether bin is a Proton launcher script rather than
+// a plain wine binary. Proton is driven as "proton run game.exe" and keeps
+// its prefix under STEAM_COMPAT_DATA_PATH instead of WINEPREFIX.
+func isProtonBinary(bin string) bool {
+	return strings.EqualFold(filepath.Base(bin), "proton")
+}
+
+// wineCommand returns the program prefix and environment that run a Windows
+// executable for the "wine" launch method. The game's own env entries win
+// over the derived prefix variables.
+func wineCommand(game Game) ([]string, map[string]string) {
+	bin := game.WineBinary
+	if bin == "" {
+		bin = "wine"
+	}
+
+	env := map[string]string{}
+	var program []string
+	if isProtonBinary(bin) {
+		program = []string{bin, "run", game.GamePath}
+		if game.WinePrefix != "" {
+			env["STEAM_COMPAT_DATA_PATH"] = game.WinePrefix
+		}
+		// Proton refuses to start without knowing where the Steam client lives.
+		for _, base := range steamBasePaths() {
+			if fileExists(base) {
+				env["STEAM_COMPAT_CLIENT_INSTALL_PATH"] = base
+				break
+			}
+		}
+	} else {
+		program = []string{bin, game.GamePath}
+		if game.WinePrefix != "" {
+			env["WINEPREFIX"] = game.WinePrefix
+		}
+	}
+
+	for k, v := range game.Env {
+		env[k] = v
+	}
+	return program, env
+}
+
+// isWineProcessFor reports whether a process is exeName running under wine.
+// Wine usually renames its loader to the .exe itself, but the kernel
+// truncates process names to 15 characters, so the command line is also
+// checked for the executable's Windows-style path.
+func isWineProcessFor(name, cmdline, exeName string) bool {
+	exeLower := strings.ToLower(exeName)
+	nameLower := strings.ToLower(name)
+	if len(exeLower) > 15 && nameLower == exeLower[:15] {
+		return true
+	}
+	if !strings.HasSuffix(exeLower, ".exe") {
+		return false
+	}
+	normalized := strings.ToLower(strings.ReplaceAll(cmdline, `\`, "/"))
+	return strings.HasPrefix(normalized, exeLower) || strings.Contains(normalized, "/"+exeLower)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func envValue(env []string, key string) (string, bool) {
+	for i := len(env) - 1; i >= 0; i-- {
+		if k, v, ok := strings.Cut(env[i], "="); ok && k == key {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func TestBuildLaunchCmd_Wine(t *testing.T) {
+	game := Game{
+		GamePath:     "/games/Witcher/witcher3.exe",
+		LaunchMethod: "wine",
+		LaunchArgs:   "-skipintro",
+		WinePrefix:   "/home/me/.wine-witcher",
+	}
+	cmd := buildLaunchCmd(game, "linux")
+	if got := strings.Join(cmd.Args, "|"); got != "wine|/games/Witcher/witcher3.exe|-skipintro" {
+		t.Errorf("args = %q", got)
+	}
+	if v, _ := envValue(cmd.Env, "WINEPREFIX"); v != "/home/me/.wine-witcher" {
+		t.Errorf("expected WINEPREFIX set, got %q", v)
+	}
+	if cmd.Dir != "/games/Witcher" {
+		t.Errorf("expected working dir to default to the exe's folder, got %q", cmd.Dir)
+	}
+}
+
+func TestBuildLaunchCmd_Proton(t *testing.T) {
+	game := Game{
+		GamePath:     "/games/Hades/Hades.exe",
+		LaunchMethod: "wine",
+		WineBinary:   "/opt/proton/proton",
+		WinePrefix:   "/home/me/compat/hades",
+		WorkingDir:   "/tmp",
+		Wrappers:     []string{"gamemoderun"},
+	}
+	cmd := buildLaunchCmd(game, "linux")
+	if got := strings.Join(cmd.Args, "|"); got != "gamemoderun|/opt/proton/proton|run|/games/Hades/Hades.exe" {
+		t.Errorf("args = %q", got)
+	}
+	if v, _ := envValue(cmd.Env, "STEAM_COMPAT_DATA_PATH"); v != "/home/me/compat/hades" {
+		t.Errorf("expected STEAM_COMPAT_DATA_PATH set, got %q", v)
+	}
+	if _, env := wineCommand(game); env["WINEPREFIX"] != "" {
+		t.Error("proton launches should not set WINEPREFIX")
+	}
+	if cmd.Dir != "/tmp" {
+		t.Errorf("explicit working_dir should win, got %q", cmd.Dir)
+	}
+}
+
+func TestWineCommand_GameEnvWins(t *testing.T) {
+	_, env := wineCommand(Game{GamePath: "/g/a.exe", WinePrefix: "/p", Env: map[string]string{"WINEPREFIX": "/override", "DXVK_HUD": "1"}})
+	if env["WINEPREFIX"] != "/override" || env["DXVK_HUD"] != "1" {
+		t.Errorf("unexpected env: %v", env)
+	}
+}
+
+func TestIsWineProcessFor(t *testing.T) {
+	cases := []struct {
+		name, cmdline, exe string
+		want               bool
+	}{
+		{"StardewModdingA", "", "StardewModdingAPI.exe", true},
+		{"wine64-preloade", `C:\Games\My Game\Game.exe -windowed`, "Game.exe", true},
+		{"wine", "wine /games/Hades/Hades.exe", "Hades.exe", true},
+		{"wineserver", "/usr/bin/wineserver", "Hades.exe", false},
+		{"bash", "bash notagame.exe.sh", "game.exe", false},
+	}
+	for _, c := range cases {
+		if got := isWineProcessFor(c.name, c.cmdline, c.exe); got != c.want {
+			t.Errorf("isWineProcessFor(%q, %q, %q) = %v, want %v", c.name, c.cmdline, c.exe, got, c.want)
+		}
+	}
+}
+
+func TestIsProcessTrackable_Wine(t *testing.T) {
+	if !isProcessTrackable(Game{LaunchMethod: "wine", GamePath: "/g/a.exe"}) {
+		t.Error("wine games should be process-trackable")
+	}
+}
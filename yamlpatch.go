@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlIndentRe finds the first indented mapping key or sequence item.
+var yamlIndentRe = regexp.MustCompile(`(?m)^( +)[^ #\n]`)
+
+// marshalPreservingYAML encodes v as YAML laid over original, the file it
+// replaces: keys, entries and list items that still exist keep their
+// comments, order, quoting and flow style, and only changed values are
+// rewritten. Keys the file doesn't have are only added when their value
+// differs from defaults, what decoding the file without them gives. Keys
+// that defaults' type doesn't define, such as typos or settings from a newer
+// version, are kept as they are. Without a usable original it falls back to
+// yaml.Marshal.
+func marshalPreservingYAML(original []byte, v, defaults any) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(original, &doc); err != nil || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return yaml.Marshal(v)
+	}
+	var updated, defaultsNode yaml.Node
+	if err := updated.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := defaultsNode.Encode(defaults); err != nil {
+		return nil, err
+	}
+	patchYAMLNode(doc.Content[0], &updated, &defaultsNode, reflect.TypeOf(defaults))
+
+	indent := 4 // yaml.Marshal's
+	if m := yamlIndentRe.FindSubmatch(original); m != nil {
+		indent = len(m[1])
+	}
+	var b bytes.Buffer
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(indent)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// patchYAMLNode updates dst in place to hold src's data, keeping dst's
+// comments and styles wherever the two line up. defaults is the matching
+// part of the defaults, or nil, and t the Go type the node decodes into, or
+// nil when unknown.
+func patchYAMLNode(dst, src, defaults *yaml.Node, t reflect.Type) {
+	if dst.Kind != src.Kind {
+		head, line, foot := dst.HeadComment, dst.LineComment, dst.FootComment
+		*dst = *src
+		dst.HeadComment, dst.LineComment, dst.FootComment = head, line, foot
+		return
+	}
+	switch dst.Kind {
+	case yaml.ScalarNode:
+		if dst.Value != src.Value || dst.Tag != src.Tag {
+			dst.Value, dst.Tag = src.Value, src.Tag
+			if dst.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+				dst.Style = src.Style
+			}
+		}
+	case yaml.MappingNode:
+		patchYAMLMapping(dst, src, defaults, t)
+	case yaml.SequenceNode:
+		patchYAMLSequence(dst, src, defaults, t)
+	}
+}
+
+// patchYAMLMapping updates each of dst's keys that src still has and drops
+// the ones it doesn't, unless t is a struct without that field. src's new
+// keys go after the key that precedes them in src, so e.g. a new version
+// field lands at the top; ones left at their default are skipped to keep
+// hand-written entries uncluttered.
+func patchYAMLMapping(dst, src, defaults *yaml.Node, t reflect.Type) {
+	fields := yamlFields(t)
+	content := make([]*yaml.Node, 0, len(src.Content))
+	for i := 0; i+1 < len(dst.Content); i += 2 {
+		key := dst.Content[i]
+		if value := mappingValue(src, key.Value); value != nil {
+			patchYAMLNode(dst.Content[i+1], value, mappingValue(defaults, key.Value), yamlValueType(t, fields, key.Value))
+			content = append(content, key, dst.Content[i+1])
+		} else if _, known := fields[key.Value]; fields != nil && !known {
+			content = append(content, key, dst.Content[i+1])
+		}
+	}
+	at := 0 // where the next new key goes
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key := src.Content[i].Value
+		if pos := mappingKeyIndex(content, key); pos >= 0 {
+			at = pos + 2
+			continue
+		}
+		if isDefaultYAMLValue(src.Content[i+1], mappingValue(defaults, key)) {
+			continue
+		}
+		newKey := src.Content[i]
+		if at == 0 && len(content) > 0 {
+			// A comment at the top of the file belongs to the file, not
+			// the key that happened to come first.
+			newKey.HeadComment, content[0].HeadComment = content[0].HeadComment, ""
+		}
+		content = append(content[:at], append([]*yaml.Node{newKey, src.Content[i+1]}, content[at:]...)...)
+		at += 2
+	}
+	dst.Content = content
+}
+
+// yamlFields maps the YAML keys of struct type t, or a pointer to one, to
+// their field types. It returns nil when t isn't a struct.
+func yamlFields(t reflect.Type) map[string]reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		switch {
+		case !f.IsExported() || key == "-":
+			continue
+		case key == "":
+			key = strings.ToLower(f.Name)
+		}
+		fields[key] = f.Type
+	}
+	return fields
+}
+
+// yamlValueType returns the type of key's value in t, given t's fields from
+// yamlFields, or of t's map values and list items. It returns nil when that
+// isn't known.
+func yamlValueType(t reflect.Type, fields map[string]reflect.Type, key string) reflect.Type {
+	if fields != nil {
+		return fields[key]
+	}
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && (t.Kind() == reflect.Map || t.Kind() == reflect.Slice) {
+		return t.Elem()
+	}
+	return nil
+}
+
+// mappingKeyIndex returns the index of key among a mapping's alternating
+// keys and values, or -1.
+func mappingKeyIndex(content []*yaml.Node, key string) int {
+	for i := 0; i+1 < len(content); i += 2 {
+		if content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// patchYAMLSequence updates dst's items to src's. Games are matched by id or
+// name, so removing or reordering one doesn't move comments onto another;
+// other lists, and renamed games without an id, are matched by position. A
+// game's defaults are the item in defaults with its id, if any.
+func patchYAMLSequence(dst, src, defaults *yaml.Node, t reflect.Type) {
+	content := make([]*yaml.Node, len(src.Content))
+	used := make([]bool, len(dst.Content))
+	for i, item := range src.Content {
+		j := matchingYAMLItem(dst, src, i, used)
+		if j < 0 {
+			content[i] = item
+			continue
+		}
+		used[j] = true
+		var itemDefaults *yaml.Node
+		if id := mappingValue(item, "id"); id != nil && defaults != nil {
+			if k := yamlItemIndex(defaults, "id", id.Value); k >= 0 {
+				itemDefaults = defaults.Content[k]
+			}
+		}
+		patchYAMLNode(dst.Content[j], item, itemDefaults, yamlValueType(t, nil, ""))
+		content[i] = dst.Content[j]
+	}
+	dst.Content = content
+}
+
+// matchingYAMLItem returns the index of the unused item in dst that
+// src.Content[i] replaces, or -1.
+func matchingYAMLItem(dst, src *yaml.Node, i int, used []bool) int {
+	for _, key := range []string{"id", "game_name"} {
+		v := mappingValue(src.Content[i], key)
+		if v == nil {
+			continue
+		}
+		for j, old := range dst.Content {
+			if w := mappingValue(old, key); !used[j] && w != nil && w.Value == v.Value {
+				return j
+			}
+		}
+	}
+	if i >= len(dst.Content) || used[i] {
+		return -1
+	}
+	// Only take the item in the same position if it isn't a game that
+	// still exists elsewhere in the list.
+	for _, key := range []string{"id", "game_name"} {
+		if old := mappingValue(dst.Content[i], key); old != nil && yamlItemIndex(src, key, old.Value) >= 0 {
+			return -1
+		}
+	}
+	return i
+}
+
+// yamlItemIndex returns the position of the first item in seq whose key is
+// value, or -1.
+func yamlItemIndex(seq *yaml.Node, key, value string) int {
+	for i, item := range seq.Content {
+		if v := mappingValue(item, key); v != nil && v.Value == value {
+			return i
+		}
+	}
+	return -1
+}
+
+// isDefaultYAMLValue reports whether n is the same as def, or, without a
+// def, empty.
+func isDefaultYAMLValue(n, def *yaml.Node) bool {
+	if def == nil {
+		return isEmptyYAMLValue(n)
+	}
+	return equalYAMLNodes(n, def) || isEmptyYAMLValue(n) && isEmptyYAMLValue(def)
+}
+
+// equalYAMLNodes reports whether a and b hold the same data.
+func equalYAMLNodes(a, b *yaml.Node) bool {
+	if a.Kind != b.Kind || len(a.Content) != len(b.Content) {
+		return false
+	}
+	if a.Kind == yaml.ScalarNode {
+		return a.Tag == b.Tag && a.Value == b.Value
+	}
+	for i := range a.Content {
+		if !equalYAMLNodes(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isEmptyYAMLValue reports whether n is an empty string, false, null or an
+// empty list or map — values that decode to the same as a missing key when
+// there's no explicit default.
+func isEmptyYAMLValue(n *yaml.Node) bool {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Tag == "!!null" || n.Tag == "!!str" && n.Value == "" || n.Tag == "!!bool" && n.Value == "false"
+	case yaml.SequenceNode, yaml.MappingNode:
+		return len(n.Content) == 0
+	}
+	return false
+}
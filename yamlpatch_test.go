@@ -0,0 +1,202 @@
+package main
+
+import (
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================================================
+// Comment-preserving saves
+// ============================================================================
+
+const commentedConfig = `# My launcher setup
+version: 2
+boot_delay: 10  # give Steam time to sign in
+
+games:
+  # Weeknight game
+  - game_name: "Stardew Valley"
+    game_path: "steam://rungameid/413150"
+    launch_method: steam
+    schedules:
+      - days: [Thu, Fri]  # not on Wednesdays
+        start_time: "19:00"
+        end_time: "21:00"
+    enabled: true
+
+  # Weekend game
+  - game_name: Celeste
+    game_path: /opt/celeste/Celeste
+    launch_method: direct
+    enabled: true
+`
+
+func decodeConfig(t *testing.T, data []byte) *Config {
+	t.Helper()
+	cfg := defaultConfig()
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		t.Fatalf("invalid YAML:\n%s\n%v", data, err)
+	}
+	return cfg
+}
+
+func TestMarshalPreservingYAML_KeepsCommentsAndStyle(t *testing.T) {
+	cfg := decodeConfig(t, []byte(commentedConfig))
+	cfg.Games[0].Enabled = false
+	cfg.Games[0].Schedules[0].EndTime = "22:00"
+
+	data, err := marshalPreservingYAML([]byte(commentedConfig), cfg, defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, s := range []string{
+		"# My launcher setup", "# give Steam time to sign in", "# Weeknight game", "# Weekend game",
+		"days: [Thu, Fri] # not on Wednesdays", `end_time: "22:00"`, `game_name: "Stardew Valley"`,
+		"\n  - game_name", // 2-space indent kept
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output missing %q:\n%s", s, out)
+		}
+	}
+	if !reflect.DeepEqual(decodeConfig(t, data), cfg) {
+		t.Errorf("output doesn't decode back to the saved config:\n%s", out)
+	}
+	// Keys the hand-written entries leave out and that are empty stay out.
+	if strings.Contains(out, "launch_args") || strings.Contains(out, "wrappers") {
+		t.Errorf("empty keys were added:\n%s", out)
+	}
+}
+
+func TestMarshalPreservingYAML_DeletedGameKeepsOthersComments(t *testing.T) {
+	cfg := decodeConfig(t, []byte(commentedConfig))
+	cfg.Games = cfg.Games[1:]
+
+	data, err := marshalPreservingYAML([]byte(commentedConfig), cfg, defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if strings.Contains(out, "Weeknight") || strings.Contains(out, "Stardew") {
+		t.Errorf("the deleted game's entry should be gone:\n%s", out)
+	}
+	if !strings.Contains(out, "# Weekend game\n  - game_name: Celeste") {
+		t.Errorf("the remaining game should keep its comment:\n%s", out)
+	}
+}
+
+func TestMarshalPreservingYAML_NewKeysFollowStructOrder(t *testing.T) {
+	original := "# settings\nboot_delay: 5\ngames: []\n"
+	cfg := decodeConfig(t, []byte(original))
+	cfg.Version = currentConfigVersion
+	cfg.StartClients = true
+	cfg.LaunchRetries = 0
+
+	defaults := defaultConfig()
+	defaults.Version = 0
+	data, err := marshalPreservingYAML([]byte(original), cfg, defaults)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	if !strings.HasPrefix(out, "# settings\nversion: ") {
+		t.Errorf("version should be added at the top:\n%s", out)
+	}
+	if !strings.Contains(out, "start_clients: true") || !strings.Contains(out, "launch_retries: 0") {
+		t.Errorf("values differing from the defaults should be added:\n%s", out)
+	}
+	if strings.Contains(out, "launch_verify_timeout") {
+		t.Errorf("values left at their defaults should not be added:\n%s", out)
+	}
+	if !reflect.DeepEqual(decodeConfig(t, data), cfg) {
+		t.Errorf("output doesn't decode back to the saved config:\n%s", out)
+	}
+}
+
+func TestMarshalPreservingYAML_KeepsUnknownKeys(t *testing.T) {
+	original := `boot_delay: 5
+future_setting: on  # from a newer version
+games:
+  - game_name: Celeste
+    game_path: /opt/celeste/Celeste
+    lauch_args: -fullscreen  # typo
+    wrappers: [gamemoderun]
+    env:
+      FNA_FORCE_VULKAN: "1"
+`
+	cfg := decodeConfig(t, []byte(original))
+	cfg.BootDelay = 8
+	cfg.Games[0].Wrappers = nil
+	cfg.Games[0].Env = nil
+
+	data, err := marshalPreservingYAML([]byte(original), cfg, defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{"future_setting: on # from a newer version", "lauch_args: -fullscreen # typo", "boot_delay: 8"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	for _, cleared := range []string{"wrappers", "env", "FNA_FORCE_VULKAN"} {
+		if strings.Contains(out, cleared) {
+			t.Errorf("cleared %s should be removed:\n%s", cleared, out)
+		}
+	}
+}
+
+func TestMarshalPreservingYAML_ExampleRoundTrip(t *testing.T) {
+	original, err := os.ReadFile("config.example.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := decodeConfig(t, original)
+	data, err := marshalPreservingYAML(original, cfg, defaultConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	comment := regexp.MustCompile(`#.*`)
+	out := string(data)
+	for _, c := range comment.FindAllString(string(original), -1) {
+		if !strings.Contains(out, c) {
+			t.Errorf("comment lost: %s", c)
+		}
+	}
+	if !reflect.DeepEqual(decodeConfig(t, data), cfg) {
+		t.Error("output doesn't decode back to the example config")
+	}
+}
+
+func TestMarshalPreservingYAML_FallsBackWithoutOriginal(t *testing.T) {
+	cfg := &Config{BootDelay: 3}
+	want, _ := yaml.Marshal(cfg)
+	for _, original := range []string{"", "games: [unclosed\n", "- just\n- a list\n"} {
+		got, err := marshalPreservingYAML([]byte(original), cfg, defaultConfig())
+		if err != nil || string(got) != string(want) {
+			t.Errorf("original %q: got %q, %v; want plain yaml.Marshal output", original, got, err)
+		}
+	}
+}
+
+func TestSaveConfig_PreservesComments(t *testing.T) {
+	app, _ := newTestApp(t)
+	os.WriteFile(app.configPath, []byte(commentedConfig), 0644)
+	app.loadConfig()
+	app.config.Games[1].Enabled = false
+	app.saveConfig()
+
+	data, _ := os.ReadFile(app.configPath)
+	if !strings.Contains(string(data), "# Weekend game") || !strings.Contains(string(data), "# give Steam time to sign in") {
+		t.Errorf("comments lost on save:\n%s", data)
+	}
+	app.loadConfig()
+	if app.config.Games[1].Enabled {
+		t.Error("the change should be saved")
+	}
+}